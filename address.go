@@ -162,6 +162,53 @@ func (c *Client) GetNextAddressPageWithPageSizeWithContext(ctx context.Context,
 	return c.ListAddressesWithContext(ctx, params)
 }
 
+// AddressIterator iterates over a paginated collection of addresses. See IterateAddresses.
+type AddressIterator struct {
+	*pageIterator
+}
+
+// Address returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *AddressIterator) Address() *Address {
+	address, _ := it.current.(*Address)
+	return address
+}
+
+// IterateAddresses returns an iterator over the addresses matching opts, fetching the pages of the collection lazily as
+// the iteration goes.
+//
+//	it := c.IterateAddresses(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Address().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateAddresses(opts *ListOptions, iterOpts *IteratorOptions) *AddressIterator {
+	return c.IterateAddressesWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateAddressesWithContext performs the same operation as IterateAddresses, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateAddressesWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *AddressIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListAddressesWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Addresses))
+		for i, address := range page.Addresses {
+			items[i] = address
+		}
+		return items, page.HasMore, nil
+	}
+	return &AddressIterator{newListIterator(ctx, "addresses", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // VerifyAddress performs address verification.
 func (c *Client) VerifyAddress(addressID string) (out *Address, err error) {
 	return c.VerifyAddressWithContext(context.Background(), addressID)
@@ -78,6 +78,52 @@ func (c *Client) ListBatchesWithContext(ctx context.Context, opts *ListOptions)
 	return
 }
 
+// BatchIterator iterates over a paginated collection of batches. See IterateBatches.
+type BatchIterator struct {
+	*pageIterator
+}
+
+// Batch returns the current item of the iterator, or nil before the first call to Next and after the iteration stopped.
+func (it *BatchIterator) Batch() *Batch {
+	batch, _ := it.current.(*Batch)
+	return batch
+}
+
+// IterateBatches returns an iterator over the batches matching opts, fetching the pages of the collection lazily as the
+// iteration goes.
+//
+//	it := c.IterateBatches(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Batch().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateBatches(opts *ListOptions, iterOpts *IteratorOptions) *BatchIterator {
+	return c.IterateBatchesWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateBatchesWithContext performs the same operation as IterateBatches, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateBatchesWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *BatchIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListBatchesWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Batch))
+		for i, batch := range page.Batch {
+			items[i] = batch
+		}
+		return items, page.HasMore, nil
+	}
+	return &BatchIterator{newListIterator(ctx, "batches", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // TODO: Add support for GetNextPage when the API supports it.
 
 // AddShipmentsToBatch adds shipments to an existing batch, and returns the
@@ -123,6 +169,12 @@ func (c *Client) BuyBatchWithContext(ctx context.Context, batchID string) (out *
 	return
 }
 
+// BuyBatchWithRequestOptions performs the same operation as BuyBatchWithContext,
+// applying the given request options to this call only.
+func (c *Client) BuyBatchWithRequestOptions(ctx context.Context, batchID string, opts ...RequestOption) (out *Batch, err error) {
+	return c.BuyBatchWithContext(ContextWithRequestOptions(ctx, opts...), batchID)
+}
+
 // GetBatch retrieves a Batch object by ID.
 func (c *Client) GetBatch(batchID string) (out *Batch, err error) {
 	return c.GetBatchWithContext(context.Background(), batchID)
@@ -38,6 +38,12 @@ func (c *Client) FundWalletWithContext(ctx context.Context, amount string, prior
 	return err
 }
 
+// FundWalletWithRequestOptions performs the same operation as FundWalletWithContext,
+// applying the given request options to this call only.
+func (c *Client) FundWalletWithRequestOptions(ctx context.Context, amount string, priority PaymentMethodPriority, opts ...RequestOption) (err error) {
+	return c.FundWalletWithContext(ContextWithRequestOptions(ctx, opts...), amount, priority)
+}
+
 // RetrievePaymentMethods returns the payment methods associated with the current user.
 func (c *Client) RetrievePaymentMethods() (out *PaymentMethod, err error) {
 	return c.RetrievePaymentMethodsWithContext(context.Background())
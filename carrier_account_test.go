@@ -50,11 +50,9 @@ func (c *ClientTests) TestCarrierAccountCreateWithCustomWorkflow() { // FedExAcc
 		assert.Equal(reflect.TypeOf(&InvalidRequestError{}), reflect.TypeOf(err))
 		assert.Equal(422, invalidRequestError.StatusCode)
 		// We expect one of the sub-errors to be regarding a missing field
-		if errorsList, ok := invalidRequestError.Errors.([]interface{}); ok {
-			if fieldError, ok := errorsList[0].(*FieldError); ok {
-				assert.Equal("shipping_streets", fieldError.Field)
-				assert.Equal("must be present and a string", fieldError.Message)
-			}
+		if errorsList, ok := invalidRequestError.Errors.([]*FieldError); ok {
+			assert.Equal("shipping_streets", errorsList[0].Field)
+			assert.Equal("must be present and a string", errorsList[0].Message)
 		}
 	}
 }
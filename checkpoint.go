@@ -0,0 +1,136 @@
+package easypost
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// PaginationCursor is the query parameter used to request the page following an object of a paginated collection.
+type PaginationCursor string
+
+const (
+	// CursorBeforeID requests the objects created before an object, used by most collections, which are listed newest
+	// first.
+	CursorBeforeID PaginationCursor = "before_id"
+	// CursorAfterID requests the objects following an object, used by the collection of child users.
+	CursorAfterID PaginationCursor = "after_id"
+)
+
+// PaginationCheckpoint is a serializable position in a paginated collection, returned by the Checkpoint method of an
+// iterator (e.g. ShipmentIterator). Once persisted, for instance as JSON, it can be passed to IteratorOptions.ResumeFrom
+// to continue the iteration right after the last item returned, with the same filters, page size and date window.
+type PaginationCheckpoint struct {
+	// Resource is the collection the checkpoint belongs to, e.g. "trackers" or "reports/shipment".
+	Resource string `json:"resource"`
+	// Filters holds the list options of the iteration, other than the cursor, the page size and the date window.
+	Filters json.RawMessage `json:"filters,omitempty"`
+	// Cursor is the query parameter used to request the next page.
+	Cursor PaginationCursor `json:"cursor"`
+	// LastID is the ID of the last item returned by the iterator. If empty, the iteration starts from the beginning.
+	LastID string `json:"last_id,omitempty"`
+	// PageSize is the number of items requested per page. If zero, the API's default is used.
+	PageSize int `json:"page_size,omitempty"`
+	// StartDateTime and EndDateTime bound the creation time of the items returned.
+	StartDateTime *DateTime `json:"start_datetime,omitempty"`
+	EndDateTime   *DateTime `json:"end_datetime,omitempty"`
+	// Done is true once the iteration reached the end of the collection or its date window.
+	Done bool `json:"done,omitempty"`
+}
+
+// paginatedListOptions is implemented by the list options of the collections that can be iterated from a
+// PaginationCheckpoint.
+type paginatedListOptions interface {
+	paginationFields() (beforeID, afterID *string, pageSize *int, startDateTime, endDateTime **DateTime)
+}
+
+func (o *ListOptions) paginationFields() (*string, *string, *int, **DateTime, **DateTime) {
+	return &o.BeforeID, &o.AfterID, &o.PageSize, &o.StartDateTime, &o.EndDateTime
+}
+
+func (o *ListShipmentsOptions) paginationFields() (*string, *string, *int, **DateTime, **DateTime) {
+	return &o.BeforeID, &o.AfterID, &o.PageSize, &o.StartDateTime, &o.EndDateTime
+}
+
+func (o *ListTrackersOptions) paginationFields() (*string, *string, *int, **DateTime, **DateTime) {
+	return &o.BeforeID, &o.AfterID, &o.PageSize, &o.StartDateTime, &o.EndDateTime
+}
+
+func (o *ListClaimsParameters) paginationFields() (*string, *string, *int, **DateTime, **DateTime) {
+	return &o.BeforeID, &o.AfterID, &o.PageSize, &o.StartDateTime, &o.EndDateTime
+}
+
+// paginationQueryFields are the JSON fields of the list options that are not filters: the cursor, the page size and the
+// date window.
+var paginationQueryFields = []string{"before_id", "after_id", "page_size", "start_datetime", "end_datetime"}
+
+// next moves the cursor of opts right after the item with the given ID, keeping every other list option.
+func (cursor PaginationCursor) next(opts paginatedListOptions, lastID string) {
+	beforeID, afterID, _, _, _ := opts.paginationFields()
+	if cursor == CursorAfterID {
+		*beforeID, *afterID = "", lastID
+	} else {
+		*beforeID, *afterID = lastID, ""
+	}
+}
+
+// newPaginationCheckpoint returns the checkpoint an iteration over a collection starts from: the one iterOpts resumes
+// from, if any, or else one built from the list options of the iteration, which are left unchanged.
+func newPaginationCheckpoint(resource string, cursor PaginationCursor, opts paginatedListOptions, iterOpts *IteratorOptions) (PaginationCheckpoint, error) {
+	if iterOpts != nil && iterOpts.ResumeFrom != nil {
+		checkpoint := *iterOpts.ResumeFrom
+		if checkpoint.Resource != resource || checkpoint.Cursor != cursor {
+			return checkpoint, newInvalidObjectError(InvalidParameter + "the checkpoint belongs to the " + checkpoint.Resource + " collection, not " + resource)
+		}
+		return checkpoint, nil
+	}
+
+	beforeID, afterID, pageSize, startDateTime, endDateTime := opts.paginationFields()
+	checkpoint := PaginationCheckpoint{
+		Resource:      resource,
+		Cursor:        cursor,
+		PageSize:      *pageSize,
+		StartDateTime: *startDateTime,
+		EndDateTime:   *endDateTime,
+	}
+	// an iteration started from a cursor is resumed from it
+	if cursor == CursorAfterID {
+		checkpoint.LastID = *afterID
+	} else {
+		checkpoint.LastID = *beforeID
+	}
+
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return checkpoint, err
+	}
+	var filters map[string]json.RawMessage
+	if err = json.Unmarshal(encoded, &filters); err != nil {
+		return checkpoint, err
+	}
+	for _, field := range paginationQueryFields {
+		delete(filters, field)
+	}
+	if len(filters) > 0 {
+		if checkpoint.Filters, err = json.Marshal(filters); err != nil {
+			return checkpoint, err
+		}
+	}
+	return checkpoint, nil
+}
+
+// restore replaces opts with the list options the iteration resumes with: the checkpoint's filters, page size and date
+// window, and the cursor following its last item.
+func (p *PaginationCheckpoint) restore(opts paginatedListOptions) error {
+	reset := reflect.ValueOf(opts).Elem()
+	reset.Set(reflect.Zero(reset.Type()))
+	if len(p.Filters) > 0 {
+		if err := json.Unmarshal(p.Filters, opts); err != nil {
+			return err
+		}
+	}
+
+	_, _, pageSize, startDateTime, endDateTime := opts.paginationFields()
+	*pageSize, *startDateTime, *endDateTime = p.PageSize, p.StartDateTime, p.EndDateTime
+	p.Cursor.next(opts, p.LastID)
+	return nil
+}
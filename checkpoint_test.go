@@ -0,0 +1,109 @@
+package easypost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestIteratorResumesFromCheckpoint() {
+	assert, require := c.Assert(), c.Require()
+
+	origin := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var objects []map[string]interface{}
+	for i := 9; i >= 0; i-- {
+		objects = append(objects, map[string]interface{}{
+			"id":         fmt.Sprintf("trk_%d", i),
+			"created_at": origin.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+		})
+	}
+	var queries []string
+	server := listServer("trackers", objects)
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return server(req)
+	})
+
+	start := DateTime(origin.Add(30 * time.Minute))
+	opts := &ListTrackersOptions{Carrier: "USPS", TrackingCodes: []string{"A", "B"}, PageSize: 3, StartDateTime: &start}
+	it := client.IterateTrackers(opts, &IteratorOptions{Limit: 4})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Tracker().ID)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"trk_9", "trk_8", "trk_7", "trk_6"}, ids)
+
+	// the checkpoint survives a round trip through JSON, e.g. to a file
+	encoded, err := json.Marshal(it.Checkpoint())
+	require.NoError(err)
+	var checkpoint PaginationCheckpoint
+	require.NoError(json.Unmarshal(encoded, &checkpoint))
+	assert.Equal("trackers", checkpoint.Resource)
+	assert.Equal(CursorBeforeID, checkpoint.Cursor)
+	assert.Equal("trk_6", checkpoint.LastID)
+	assert.Equal(3, checkpoint.PageSize)
+	assert.JSONEq(`{"carrier": "USPS", "tracking_codes": ["A", "B"]}`, string(checkpoint.Filters))
+	assert.False(checkpoint.Done)
+
+	queries = nil
+	it = client.IterateTrackers(nil, &IteratorOptions{ResumeFrom: &checkpoint})
+	ids = nil
+	for it.Next() {
+		ids = append(ids, it.Tracker().ID)
+	}
+	require.NoError(it.Err())
+	// trk_0 is created before the start of the date window
+	assert.Equal([]string{"trk_5", "trk_4", "trk_3", "trk_2", "trk_1"}, ids)
+	// every page of the resumed iteration keeps the filters of the checkpoint
+	assert.Equal([]string{
+		"before_id=trk_6&carrier=USPS&page_size=3&start_datetime=2026-01-01T00%3A30%3A00Z&tracking_codes=A&tracking_codes=B",
+		"before_id=trk_3&carrier=USPS&page_size=3&start_datetime=2026-01-01T00%3A30%3A00Z&tracking_codes=A&tracking_codes=B",
+	}, queries)
+	assert.True(it.Checkpoint().Done)
+
+	// a finished iteration has nothing left to fetch
+	queries = nil
+	it = client.IterateTrackers(nil, &IteratorOptions{ResumeFrom: it.Checkpoint()})
+	assert.False(it.Next())
+	require.NoError(it.Err())
+	assert.Empty(queries)
+}
+
+func (c *ClientTests) TestChildUserCheckpointUsesAfterID() {
+	assert, require := c.Assert(), c.Require()
+
+	var queries []string
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return scriptedResponse(200, `{"children": [{"id": "user_1"}, {"id": "user_2"}], "has_more": true}`, nil), nil
+	})
+
+	it := client.IterateChildUsers(&ListOptions{PageSize: 2}, &IteratorOptions{Limit: 1})
+	require.True(it.Next())
+	assert.False(it.Next())
+	checkpoint := it.Checkpoint()
+	assert.Equal(CursorAfterID, checkpoint.Cursor)
+	assert.Equal("user_1", checkpoint.LastID)
+	assert.Nil(checkpoint.Filters)
+
+	it = client.IterateChildUsers(nil, &IteratorOptions{ResumeFrom: checkpoint, Limit: 1})
+	require.True(it.Next())
+	assert.Equal("after_id=user_1&page_size=2", queries[len(queries)-1])
+}
+
+func (c *ClientTests) TestCheckpointFromAnotherCollection() {
+	assert := c.Assert()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{"shipments": []}`, nil))
+
+	checkpoint := &PaginationCheckpoint{Resource: "trackers", Cursor: CursorBeforeID, LastID: "trk_1"}
+	it := client.IterateShipments(nil, &IteratorOptions{ResumeFrom: checkpoint})
+	assert.False(it.Next())
+
+	var invalidObjectError *InvalidObjectError
+	assert.True(errors.As(it.Err(), &invalidObjectError))
+	assert.Equal(0, *calls)
+}
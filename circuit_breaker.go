@@ -0,0 +1,196 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerCoolDown         = 30 * time.Second
+)
+
+// CircuitBreakerState is the state of a CircuitBreaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed lets every API call through.
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen fails every API call fast with a CircuitBreakerOpenError until the cool-down has elapsed.
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen lets a single probe API call through to decide whether to close or re-open the circuit.
+	CircuitBreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops a Client from sending API calls while the API appears to be unavailable, so that callers fail
+// fast instead of waiting for the full timeout on every call.
+//
+// The circuit opens after FailureThreshold consecutive InternalServerError, GatewayTimeoutError or transport failures.
+// While open, API calls fail immediately with a CircuitBreakerOpenError. Once CoolDown has elapsed, the circuit becomes
+// half-open and lets a single probe call through: the circuit closes if it succeeds, and re-opens if it fails. The
+// outcome of calls sent before the circuit opened is ignored until it closes again.
+//
+// State changes are reported to the CircuitBreakerHookEventSubscribers registered in the Client's Hooks.
+// A CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit. If zero, a default of 5 will be used.
+	FailureThreshold int
+	// CoolDown is how long the circuit stays open before probing the API again. If zero, a default of 30 seconds will be used.
+	CoolDown time.Duration
+
+	mu                  sync.Mutex
+	state               CircuitBreakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens after failureThreshold consecutive failures and probes the API
+// again after coolDown.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{FailureThreshold: failureThreshold, CoolDown: coolDown}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitBreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitBreakerOpen && time.Since(b.openedAt) >= b.coolDown() {
+		return CircuitBreakerHalfOpen
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) failureThreshold() int {
+	if b.FailureThreshold <= 0 {
+		return defaultCircuitBreakerFailureThreshold
+	}
+	return b.FailureThreshold
+}
+
+func (b *CircuitBreaker) coolDown() time.Duration {
+	if b.CoolDown <= 0 {
+		return defaultCircuitBreakerCoolDown
+	}
+	return b.CoolDown
+}
+
+// allow reports whether an API call may be sent and whether it is the half-open probe, along with the state change
+// it caused, if any.
+func (b *CircuitBreaker) allow() (allowed bool, probe bool, event *CircuitBreakerHookEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitBreakerOpen && time.Since(b.openedAt) >= b.coolDown() {
+		event = b.transition(CircuitBreakerHalfOpen)
+	}
+
+	switch b.state {
+	case CircuitBreakerOpen:
+		return false, false, event
+	case CircuitBreakerHalfOpen:
+		if b.probing {
+			return false, false, event
+		}
+		b.probing = true
+		return true, true, event
+	default:
+		return true, false, event
+	}
+}
+
+// record updates the circuit with the outcome of an API call that was allowed through, returning the state change it
+// caused, if any.
+func (b *CircuitBreaker) record(err error, probe bool) *CircuitBreakerHookEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	var requestHookError *RequestHookError
+	switch {
+	case errors.Is(err, context.Canceled), errors.As(err, &requestHookError):
+		// the caller gave up or a hook vetoed the call, which says nothing about the health of the API
+		return nil
+	case !probe && b.state != CircuitBreakerClosed:
+		// the call was sent before the circuit opened, so only the half-open probe decides whether it closes again
+		return nil
+	case isCircuitBreakerFailure(err):
+		b.consecutiveFailures++
+		if probe || b.consecutiveFailures >= b.failureThreshold() {
+			b.openedAt = time.Now()
+			return b.transition(CircuitBreakerOpen)
+		}
+		return nil
+	default:
+		b.consecutiveFailures = 0
+		if b.state != CircuitBreakerClosed {
+			return b.transition(CircuitBreakerClosed)
+		}
+		return nil
+	}
+}
+
+// transition moves the circuit to the given state. The caller must hold the lock.
+func (b *CircuitBreaker) transition(to CircuitBreakerState) *CircuitBreakerHookEvent {
+	event := &CircuitBreakerHookEvent{
+		From:                b.state,
+		To:                  to,
+		ConsecutiveFailures: b.consecutiveFailures,
+		Timestamp:           time.Now(),
+	}
+	b.state = to
+	return event
+}
+
+// isCircuitBreakerFailure returns true if the error indicates that the API is unavailable.
+func isCircuitBreakerFailure(err error) bool {
+	var internalServerError *InternalServerError
+	var gatewayTimeoutError *GatewayTimeoutError
+	return errors.As(err, &internalServerError) || errors.As(err, &gatewayTimeoutError) || isTransportError(err)
+}
+
+// allowCircuitBreaker checks whether the client's circuit breaker lets an API call through, and whether the call is
+// the half-open probe.
+func (c *Client) allowCircuitBreaker(ctx context.Context) (probe bool, err error) {
+	if c.CircuitBreaker == nil {
+		return false, nil
+	}
+	allowed, probe, event := c.CircuitBreaker.allow()
+	if event != nil {
+		c.Hooks.executeCircuitBreakerHooks(ctx, *event)
+	}
+	if !allowed {
+		return false, newCircuitBreakerOpenError()
+	}
+	return probe, nil
+}
+
+// recordCircuitBreaker reports the outcome of an API call to the client's circuit breaker.
+func (c *Client) recordCircuitBreaker(ctx context.Context, err error, probe bool) {
+	if c.CircuitBreaker == nil {
+		return
+	}
+	if event := c.CircuitBreaker.record(err, probe); event != nil {
+		c.Hooks.executeCircuitBreakerHooks(ctx, *event)
+	}
+}
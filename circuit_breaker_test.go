@@ -0,0 +1,179 @@
+package easypost
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestCircuitBreakerOpensAfterConsecutiveFailures() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(500, `{}`, nil))
+	client.CircuitBreaker = NewCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetAddress("adr_123")
+		var internalServerError *InternalServerError
+		require.True(errors.As(err, &internalServerError))
+	}
+	assert.Equal(CircuitBreakerOpen, client.CircuitBreaker.State())
+
+	// while open, calls fail fast without reaching the API
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	var circuitBreakerOpenError *CircuitBreakerOpenError
+	assert.True(errors.As(err, &circuitBreakerOpenError))
+	var localError *LocalError
+	assert.True(errors.As(err, &localError))
+	assert.Equal(3, *calls)
+}
+
+func (c *ClientTests) TestCircuitBreakerIgnoresClientErrors() {
+	assert := c.Assert()
+
+	client, calls := c.ScriptedClient(
+		respondWith(500, `{}`, nil),
+		respondWith(500, `{}`, nil),
+		respondWith(404, `{}`, nil),
+		respondWith(500, `{}`, nil),
+	)
+	client.CircuitBreaker = NewCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		_, _ = client.GetAddress("adr_123")
+	}
+
+	// the 404 shows the API is up and resets the consecutive failure count
+	assert.Equal(CircuitBreakerClosed, client.CircuitBreaker.State())
+
+	_, _ = client.GetAddress("adr_123")
+	assert.Equal(CircuitBreakerOpen, client.CircuitBreaker.State())
+	assert.Equal(6, *calls)
+}
+
+func (c *ClientTests) TestCircuitBreakerTransportErrors() {
+	assert := c.Assert()
+
+	for _, test := range []struct {
+		err   error
+		state CircuitBreakerState
+	}{
+		{errors.New("connection reset by peer"), CircuitBreakerOpen},
+		{context.DeadlineExceeded, CircuitBreakerOpen},
+		// SSL and proxy failures are configuration problems rather than signs that the API is down
+		{x509.UnknownAuthorityError{}, CircuitBreakerClosed},
+		{&net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}, CircuitBreakerClosed},
+	} {
+		transportErr := test.err
+		client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+			return nil, transportErr
+		})
+		client.CircuitBreaker = NewCircuitBreaker(2, time.Hour)
+
+		for i := 0; i < 2; i++ {
+			_, _ = client.GetAddress("adr_123")
+		}
+		assert.Equal(test.state, client.CircuitBreaker.State(), transportErr.Error())
+	}
+}
+
+func (c *ClientTests) TestCircuitBreakerHalfOpenProbe() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(
+		respondWith(504, `{}`, nil),
+		respondWith(504, `{}`, nil),
+		respondWith(200, `{"id": "adr_123"}`, nil),
+	)
+	client.CircuitBreaker = NewCircuitBreaker(1, 10*time.Millisecond)
+
+	var events []CircuitBreakerHookEvent
+	client.Hooks.AddCircuitBreakerEventSubscriber(CircuitBreakerHookEventSubscriber{
+		Callback: func(ctx context.Context, event CircuitBreakerHookEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	})
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	assert.Equal(CircuitBreakerOpen, client.CircuitBreaker.State())
+
+	// the failed probe re-opens the circuit
+	time.Sleep(15 * time.Millisecond)
+	assert.Equal(CircuitBreakerHalfOpen, client.CircuitBreaker.State())
+	_, err = client.GetAddress("adr_123")
+	require.Error(err)
+	assert.Equal(CircuitBreakerOpen, client.CircuitBreaker.State())
+
+	// the successful probe closes the circuit
+	time.Sleep(15 * time.Millisecond)
+	_, err = client.GetAddress("adr_123")
+	require.NoError(err)
+	assert.Equal(CircuitBreakerClosed, client.CircuitBreaker.State())
+	assert.Equal(3, *calls)
+
+	var transitions []string
+	for _, event := range events {
+		transitions = append(transitions, event.From.String()+"->"+event.To.String())
+	}
+	assert.Equal([]string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func (c *ClientTests) TestCircuitBreakerSingleProbe() {
+	assert := c.Assert()
+
+	breaker := NewCircuitBreaker(1, time.Millisecond)
+	breaker.record(&InternalServerError{}, false)
+	time.Sleep(2 * time.Millisecond)
+
+	allowed, probe, event := breaker.allow()
+	assert.True(allowed)
+	assert.True(probe)
+	assert.Equal(CircuitBreakerHalfOpen, event.To)
+
+	// only one probe is let through while half-open
+	allowed, _, _ = breaker.allow()
+	assert.False(allowed)
+
+	// a cancelled probe leaves the circuit half-open for the next caller
+	assert.Nil(breaker.record(context.Canceled, true))
+	allowed, probe, _ = breaker.allow()
+	assert.True(allowed)
+	assert.True(probe)
+}
+
+func (c *ClientTests) TestCircuitBreakerIgnoresCallsSentBeforeOpening() {
+	assert := c.Assert()
+
+	breaker := NewCircuitBreaker(1, time.Hour)
+	slowAllowed, slowProbe, _ := breaker.allow()
+	assert.True(slowAllowed)
+	_, failingProbe, _ := breaker.allow()
+	assert.NotNil(breaker.record(&InternalServerError{}, failingProbe))
+	assert.Equal(CircuitBreakerOpen, breaker.State())
+
+	// a slow call sent while the circuit was closed succeeds after it opened: the circuit stays open
+	assert.Nil(breaker.record(nil, slowProbe))
+	assert.Equal(CircuitBreakerOpen, breaker.State())
+
+	// once half-open, only the probe's outcome counts
+	breaker.openedAt = time.Now().Add(-2 * time.Hour)
+	allowed, probe, event := breaker.allow()
+	assert.True(allowed)
+	assert.True(probe)
+	assert.Equal(CircuitBreakerHalfOpen, event.To)
+	assert.Nil(breaker.record(nil, false))
+	assert.Equal(CircuitBreakerHalfOpen, breaker.State())
+	assert.Equal(CircuitBreakerClosed, breaker.record(nil, probe).To)
+}
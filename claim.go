@@ -132,6 +132,52 @@ func (c *Client) GetNextClaimPageWithPageSizeWithContext(ctx context.Context, co
 	return c.ListClaimsWithContext(ctx, claimParams)
 }
 
+// ClaimIterator iterates over a paginated collection of claims. See IterateClaims.
+type ClaimIterator struct {
+	*pageIterator
+}
+
+// Claim returns the current item of the iterator, or nil before the first call to Next and after the iteration stopped.
+func (it *ClaimIterator) Claim() *Claim {
+	claim, _ := it.current.(*Claim)
+	return claim
+}
+
+// IterateClaims returns an iterator over the claims matching opts, fetching the pages of the collection lazily as the
+// iteration goes.
+//
+//	it := c.IterateClaims(&easypost.ListClaimsParameters{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Claim().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateClaims(opts *ListClaimsParameters, iterOpts *IteratorOptions) *ClaimIterator {
+	return c.IterateClaimsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateClaimsWithContext performs the same operation as IterateClaims, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateClaimsWithContext(ctx context.Context, opts *ListClaimsParameters, iterOpts *IteratorOptions) *ClaimIterator {
+	var filters ListClaimsParameters
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListClaimsWithContext(ctx, opts.(*ListClaimsParameters))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Claims))
+		for i, claim := range page.Claims {
+			items[i] = claim
+		}
+		return items, page.HasMore, nil
+	}
+	return &ClaimIterator{newListIterator(ctx, "claims", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // GetClaim returns the Claim object with the given ID or reference.
 func (c *Client) GetClaim(claimID string) (out *Claim, err error) {
 	return c.GetClaimWithContext(context.Background(), claimID)
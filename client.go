@@ -7,9 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -22,6 +24,7 @@ var apiBaseURL = &url.URL{
 
 var defaultUserAgent string
 var defaultTimeout int
+var defaultConnectTimeout int
 
 func init() {
 	// We skip grabbing the OS version (for now) as there is not a reliable way to do so across OS's
@@ -29,6 +32,7 @@ func init() {
 		"EasyPost/v2 GoClient/%s Go/%s OS/%s OSVersion/%s OSArch/%s",
 		Version, runtime.Version(), runtime.GOOS, "NA", runtime.GOARCH)
 	defaultTimeout = 60000
+	defaultConnectTimeout = 30000
 }
 
 // A Client provides an HTTP client for EasyPost API operations.
@@ -37,8 +41,9 @@ type Client struct {
 	// ResolveReference to create request URLs. (If 'Path' is specified, it
 	// should end with a trailing slash.) If nil, the default will be used.
 	BaseURL *url.URL
-	// Client is an HTTP client used to make API requests. If nil,
-	// http.DefaultClient will be used.
+	// Client is an HTTP client used to make API requests. It is never modified
+	// by this library. If nil, an HTTP client owned by this Client will be
+	// used, configured with ConnectTimeout and ResponseHeaderTimeout.
 	Client *http.Client
 	// APIKey is the user's API key. It is required.
 	// Note: Treat your API Keys as passwords—keep them secret. API Keys give
@@ -50,12 +55,87 @@ type Client struct {
 	UserAgent string
 	// Timeout specifies the time limit (in milliseconds) for requests made by this Client. The
 	// timeout includes connection time, any redirects, and reading the
-	// response body.
+	// response body. It is applied to each attempt of a request through a
+	// context deadline. If zero, a default of 60 seconds will be used.
 	Timeout int
-	// MockRequests is a list of requests that will be mocked by the client.
+	// ConnectTimeout specifies the time limit (in milliseconds) for establishing
+	// a connection to the API. It only applies when Client is nil, and is read
+	// when the first request is made. If zero, a default of 30 seconds will be used.
+	ConnectTimeout int
+	// ResponseHeaderTimeout specifies the time limit (in milliseconds) to wait for
+	// the API's response headers after the request has been sent. It only
+	// applies when Client is nil, and is read when the first request is made.
+	// If zero, there is no limit other than Timeout.
+	ResponseHeaderTimeout int
+	// Proxy is the URL of the proxy used to reach the API. It only applies when
+	// Client is nil. If nil, the proxy is read from the HTTP_PROXY, HTTPS_PROXY
+	// and NO_PROXY environment variables.
+	Proxy *url.URL
+	// MockRequests is a list of requests that will be mocked by the client. The requests they receive are available
+	// from MockCalls, and VerifyMockRequests checks that each of them was hit.
 	MockRequests []MockRequest
 	// Hooks is a collection of HookEventSubscriber instances for various hooks available in the client
 	Hooks Hooks
+	// AutoIdempotencyKeys enables generating an Idempotency-Key header for every POST request that was not given one
+	// through ContextWithIdempotencyKey. The same key is sent on every retry attempt of the request.
+	AutoIdempotencyKeys bool
+	// RetryPolicy configures automatic retries of API calls that fail with a transient error. If nil, API calls are
+	// never retried.
+	RetryPolicy *RetryPolicy
+	// RateLimiter throttles API calls before they are sent, including retry attempts. If nil, API calls are not
+	// throttled.
+	RateLimiter *RateLimiter
+	// CircuitBreaker fails API calls fast while the API appears to be unavailable. If nil, API calls are always
+	// attempted.
+	CircuitBreaker *CircuitBreaker
+	// Middlewares wrap each attempt of an API call, the first one being the outermost. See Middleware.
+	Middlewares []Middleware
+	// Logger logs each attempt of an API call with its method, path, status code, duration and request ID. It is
+	// satisfied by *slog.Logger. If nil, API calls are not logged.
+	Logger Logger
+	// LogBodies includes the request and response bodies of API calls in the logs, with the sensitive fields listed
+	// by LogRedactor removed.
+	LogBodies bool
+	// LogRedactor lists the fields removed from logged bodies. If nil, DefaultRedactor will be used.
+	LogRedactor *Redactor
+	// Tracer opens a span for each API call. If nil, API calls are not traced.
+	Tracer Tracer
+	// Metrics receives the request counts, latencies, errors and label spend of the API calls. If nil, no metrics are
+	// recorded.
+	Metrics MetricsCollector
+	// SafeMode refuses purchase API calls (buying shipments, including creating them with a service in one call,
+	// batches, orders, pickups and Luma shipments, insuring and funding the wallet) with a ProductionModeError unless the client is in test mode (see Mode). A purchase can
+	// be let through for a single call with the AllowProductionPurchase request option.
+	SafeMode bool
+	// DryRun enables dry-run mode: mutating API calls are captured by the DryRun and answered with synthetic objects
+	// instead of being sent, while GET calls go through as usual. SafeMode does not refuse purchases in dry-run mode.
+	// If nil, every API call is sent.
+	DryRun *DryRun
+
+	// internals is held behind a pointer so that a Client can be copied by value. Copies made after first use share it.
+	internals *clientInternals
+}
+
+// clientInternals is the state a Client builds up as it is used.
+type clientInternals struct {
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
+
+	mockState *mockState
+
+	modeMu          sync.Mutex
+	detectedMode    Mode
+	detectedModeKey string
+}
+
+// state returns the internal state of the Client, creating it on first use.
+func (c *Client) state() *clientInternals {
+	lazyInitMu.Lock()
+	defer lazyInitMu.Unlock()
+	if c.internals == nil {
+		c.internals = &clientInternals{mockState: &mockState{}}
+	}
+	return c.internals
 }
 
 // New returns a new Client with the given API key.
@@ -86,13 +166,81 @@ func (c *Client) timeout() time.Duration {
 	return time.Duration(timeout) * time.Millisecond
 }
 
+func (c *Client) connectTimeout() time.Duration {
+	timeout := c.ConnectTimeout
+	if c.ConnectTimeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// client returns the HTTP client used to make API requests. A caller-supplied
+// http.Client is returned as-is.
 func (c *Client) client() *http.Client {
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
+	if c.Client != nil {
+		return c.Client
+	}
+	state := c.state()
+	state.defaultClientOnce.Do(func() {
+		state.defaultClient = &http.Client{Transport: c.sharedTransport()}
+	})
+	return state.defaultClient
+}
+
+// transportConfig holds the Client settings applied to a library-owned transport.
+type transportConfig struct {
+	connectTimeout        time.Duration
+	responseHeaderTimeout int
+	proxy                 string
+}
+
+var (
+	sharedTransportsMu sync.Mutex
+	sharedTransports   = map[transportConfig]*http.Transport{}
+)
+
+// sharedTransport returns the library-owned transport for the Client's ConnectTimeout, ResponseHeaderTimeout and
+// Proxy. Clients with the same settings share a transport, and its pool of connections, rather than each opening
+// their own.
+func (c *Client) sharedTransport() *http.Transport {
+	config := transportConfig{connectTimeout: c.connectTimeout(), responseHeaderTimeout: c.ResponseHeaderTimeout}
+	if c.Proxy != nil {
+		config.proxy = c.Proxy.String()
+	}
+
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+	transport, ok := sharedTransports[config]
+	if !ok {
+		transport = c.newTransport()
+		sharedTransports[config] = transport
+	}
+	return transport
+}
+
+// newTransport returns a transport applying the Client's ConnectTimeout, ResponseHeaderTimeout and Proxy.
+func (c *Client) newTransport() *http.Transport {
+	var transport *http.Transport
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = defaultTransport.Clone()
+	} else {
+		transport = &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+		}
+	}
+	transport.DialContext = (&net.Dialer{
+		Timeout:   c.connectTimeout(),
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	if c.Proxy != nil {
+		transport.Proxy = http.ProxyURL(c.Proxy)
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = time.Duration(c.ResponseHeaderTimeout) * time.Millisecond
 	}
-	client.Timeout = c.timeout()
-	return client
+	return transport
 }
 
 func (c *Client) setParameters(req *http.Request, params interface{}) error {
@@ -131,14 +279,51 @@ func (c *Client) setParameters(req *http.Request, params interface{}) error {
 	}
 }
 
-func (c *Client) do(ctx context.Context, method, path string, params interface{}, out interface{}) error {
+func (c *Client) do(ctx context.Context, method, path string, params interface{}, out interface{}) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	route := templatePath(path)
+	ctx, span := c.tracer().Start(ctx, method+" "+route)
+	span.SetAttribute(SpanAttributeHTTPMethod, method)
+	span.SetAttribute(SpanAttributeHTTPRoute, route)
+
+	start := time.Now()
+	var statusCode int
+	defer func() {
+		if err != nil {
+			span.SetAttribute(SpanAttributeErrorType, errorTypeName(err))
+			span.RecordError(err)
+		}
+		span.End()
+		c.recordMetrics(method, route, statusCode, time.Since(start), err)
+	}()
+
 	if c.APIKey == "" {
 		return newMissingPropertyError("APIKey")
 	}
 
+	options := requestOptionsFromContext(ctx)
+	if err := c.checkSafeMode(method, route, params, options); err != nil {
+		return err
+	}
+	if options.idempotencyKey != "" && options.idempotencyCall != nil {
+		if err := options.idempotencyCall.bind(method, path); err != nil {
+			return err
+		}
+	}
+
+	baseURL := c.baseURL()
+	if options.basePath != "" {
+		withBasePath := *baseURL
+		withBasePath.Path = options.basePath
+		baseURL = &withBasePath
+	}
+
 	req := &http.Request{
 		Method: method,
-		URL:    c.baseURL().ResolveReference(&url.URL{Path: path}),
+		URL:    baseURL.ResolveReference(&url.URL{Path: path}),
 		Header: make(http.Header, 2),
 	}
 
@@ -146,94 +331,153 @@ func (c *Client) do(ctx context.Context, method, path string, params interface{}
 	if err := c.setParameters(req, params); err != nil {
 		return err
 	}
+	for key, values := range options.headers {
+		req.Header[key] = values
+	}
 
 	req.SetBasicAuth(c.APIKey, "")
-	if ctx != nil {
-		req = req.WithContext(ctx)
+	req = req.WithContext(ctx)
+
+	idempotencyKey := c.idempotencyKey(ctx, method)
+	if idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
 	}
 
-	var res *http.Response
-	var err error
+	res, err := c.execute(ctx, req, path, out)
+	if res != nil {
+		statusCode = res.StatusCode
+		span.SetAttribute(SpanAttributeHTTPStatusCode, res.StatusCode)
+		if requestID := res.Header.Get(RequestIDHeader); requestID != "" {
+			span.SetAttribute(SpanAttributeRequestID, requestID)
+		}
+	}
 
-	// prepare and execute request hook(s)
-	requestId := uuid.New()
-	requestTimestamp := time.Now()
-	requestEvent := &RequestHookEvent{
-		Method:           req.Method,
-		Url:              req.URL,
-		RequestBody:      req.Body,
-		Headers:          req.Header,
-		RequestTimestamp: requestTimestamp,
-		Id:               requestId,
+	var metadata *ResponseMetadata
+	if res != nil {
+		metadata = newResponseMetadata(req, res, time.Since(start))
+		if options.responseMetadata != nil {
+			*options.responseMetadata = *metadata
+		}
+		if options.capturedMetadata != nil {
+			*options.capturedMetadata = *metadata
+		}
+		body, _ := io.ReadAll(res.Body)
+		if options.rawResponse != nil {
+			*options.rawResponse = RawResponse{
+				StatusCode: res.StatusCode,
+				Header:     res.Header.Clone(),
+				Body:       body,
+			}
+		}
+		if err == nil {
+			c.warnOnModeMismatch(ctx, req, body)
+		}
 	}
 
-	// loop over each request hook and execute it
-	for _, hook := range c.Hooks.RequestHookEventSubscriptions {
-		hook.Execute(ctx, *requestEvent)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.IdempotencyKey = idempotencyKey
+		apiErr.Metadata = metadata
 	}
 
-	if len(c.MockRequests) > 0 {
-		// If there are mock requests set, this client will ONLY make mock requests
-		res = c.findMatchingMockRequest(req)
-		if res == nil {
-			return errors.New("no matching mock request found")
+	return err
+}
+
+// execute sends a prepared request, retrying it according to the client's RetryPolicy, and decodes a successful
+// response into out. The last response received is returned, if any.
+func (c *Client) execute(ctx context.Context, req *http.Request, path string, out interface{}) (*http.Response, error) {
+	// the same ID is shared by every attempt of this call so hooks can correlate retries
+	requestId := uuid.New()
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			// rewind the request body for the next attempt
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
 		}
-	} else {
-		// Otherwise, make a real request
-		res, err = c.client().Do(req)
-	}
 
-	if err != nil {
-		// prepare and execute response hook(s) for failed requests
-		responseEvent := &ResponseHookEvent{
-			HttpStatus:        0,
-			Method:            req.Method,
-			Url:               req.URL,
-			ResponseBody:      nil,
-			Headers:           nil,
-			RequestTimestamp:  requestTimestamp,
-			ResponseTimestamp: time.Now(),
-			Id:                requestId,
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx, path); err != nil {
+				return nil, err
+			}
+		}
+
+		probe, err := c.allowCircuitBreaker(ctx)
+		if err != nil {
+			return nil, err
 		}
-		// loop over each response hook and execute it
-		for _, hook := range c.Hooks.ResponseHookEventSubscriptions {
-			hook.Execute(ctx, *responseEvent)
+
+		res, body, err := c.sendWithTimeout(ctx, req, requestId, attempt)
+		if errors.Is(err, errNoMatchingMockRequest) {
+			c.recordCircuitBreaker(ctx, nil, probe)
+			return nil, err
 		}
 
-		return err
+		var retryAfter time.Duration
+		if err == nil {
+			// status code is 2xx, no error occurred
+			if res.StatusCode >= 200 && res.StatusCode <= 299 {
+				c.recordCircuitBreaker(ctx, nil, probe)
+				if out != nil {
+					err = json.NewDecoder(bytes.NewReader(body)).Decode(out)
+				}
+				return res, err
+			}
+
+			// status code is not 2xx, an error occurred
+			retryAfter = parseRetryAfter(res.Header.Get("Retry-After"))
+			err = BuildErrorFromResponse(res)
+			// keep the body readable by the caller
+			res.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		c.recordCircuitBreaker(ctx, err, probe)
+
+		if !c.RetryPolicy.shouldRetry(req, err, attempt) {
+			return res, err
+		}
+		backoff := c.RetryPolicy.backoff(attempt, err, retryAfter)
+		// the next attempt could not start before the caller's deadline, so there is no point in waiting for it
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(backoff).After(deadline) {
+			return res, withContextError(err, context.DeadlineExceeded)
+		}
+		if sleepErr := sleepWithContext(ctx, backoff); sleepErr != nil {
+			return res, withContextError(err, sleepErr)
+		}
 	}
+}
 
-	// prepare and execute response hook(s) for successful requests
-	responseEvent := &ResponseHookEvent{
-		HttpStatus:        res.StatusCode,
-		Method:            req.Method,
-		Url:               req.URL,
-		ResponseBody:      res.Body,
-		Headers:           res.Header,
-		RequestTimestamp:  requestTimestamp,
-		ResponseTimestamp: time.Now(),
-		Id:                requestId,
+// sendWithTimeout executes a single attempt of a request under the client's
+// Timeout, or the one set with WithRequestTimeout, buffering the response body so it
+// can be read after the attempt's deadline has been released.
+func (c *Client) sendWithTimeout(ctx context.Context, req *http.Request, requestId uuid.UUID, attempt int) (*http.Response, []byte, error) {
+	timeout := c.timeout()
+	if override := requestOptionsFromContext(ctx).timeout; override > 0 {
+		timeout = override
 	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// loop over each response hook and execute it
-	for _, hook := range c.Hooks.ResponseHookEventSubscriptions {
-		hook.Execute(ctx, *responseEvent)
+	res, err := c.send(attemptCtx, req, requestId, attempt)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	defer func() { _ = res.Body.Close() }()
-
-	// status code is 2xx, no error occurred
-	if res.StatusCode >= 200 && res.StatusCode <= 299 {
-		if out != nil {
-			return json.NewDecoder(res.Body).Decode(out)
-		}
-		return nil
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, newTransportError(err)
 	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
 
-	// status code is not 2xx, an error occurred
-	apiErr := BuildErrorFromResponse(res)
+	return res, body, nil
+}
 
-	return apiErr
+// send executes a single attempt of a request through the client's middleware chain.
+func (c *Client) send(ctx context.Context, req *http.Request, requestId uuid.UUID, attempt int) (*http.Response, error) {
+	return c.handler()(req.WithContext(contextWithRequestAttempt(ctx, requestId, attempt)))
 }
 
 // MakeAPICall makes an API call to the EasyPost API.
@@ -242,16 +486,40 @@ func (c *Client) do(ctx context.Context, method, path string, params interface{}
 // are not yet supported by the client library's services. When possible, the service for your use case
 // should be used instead as it provides a more convenient and higher-level interface depending on the endpoint.
 func (c *Client) MakeAPICall(method, endpoint string, params map[string]interface{}) (out map[string]interface{}, err error) {
-	// For GET/DELETE requests, convert map to url.Values since query package doesn't handle maps
-	var paramsToUse interface{} = params
-	if method == http.MethodGet || method == http.MethodDelete {
-		values := url.Values{}
-		for key, value := range params {
-			values.Set(key, fmt.Sprintf("%v", value))
+	_, err = c.MakeAPICallWithContext(context.Background(), method, endpoint, params, &out)
+	return
+}
+
+// MakeAPICallWithContext performs the same operation as MakeAPICall, but allows specifying a context that can
+// interrupt the request, and decodes the response into out instead of a map.
+//
+// The params can be a struct, a map or url.Values, and are sent as query parameters for GET and DELETE requests
+// or as a JSON body otherwise. out can be a pointer to any value the response decodes into, or nil to discard the
+// response. The metadata of the response is returned whenever the API returned one, along with the same typed errors
+// as the services (e.g. a NotFoundError).
+//
+//	var out struct {
+//		ID string `json:"id"`
+//	}
+//	metadata, err := client.MakeAPICallWithContext(ctx, http.MethodPost, "luma/promise", params, &out)
+func (c *Client) MakeAPICallWithContext(ctx context.Context, method, endpoint string, params, out interface{}) (*ResponseMetadata, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// For GET/DELETE requests, convert maps to url.Values since the query package doesn't handle maps
+	if values, ok := params.(map[string]interface{}); ok && (method == http.MethodGet || method == http.MethodDelete) {
+		urlValues := url.Values{}
+		for key, value := range values {
+			urlValues.Set(key, fmt.Sprintf("%v", value))
 		}
-		paramsToUse = values
+		params = urlValues
 	}
 
-	err = c.do(context.Background(), method, endpoint, paramsToUse, &out)
-	return
+	var metadata ResponseMetadata
+	err := c.do(ContextWithRequestOptions(ctx, captureResponseMetadata(&metadata)), method, endpoint, params, out)
+	if metadata.StatusCode == 0 {
+		return nil, err
+	}
+	return &metadata, err
 }
@@ -2,6 +2,12 @@ package easypost
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -168,6 +174,154 @@ func (c *ClientTests) TestHooksUnsubscribing() {
 	assert.Equal(1, requestCallbackCallCount)
 }
 
+func (c *ClientTests) TestHooksReceiveBodyCopies() {
+	assert, require := c.Assert(), c.Require()
+
+	var sentBody []byte
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sentBody, _ = io.ReadAll(req.Body)
+		return scriptedResponse(200, `{"id": "adr_123", "object": "Address"}`, nil), nil
+	})
+
+	var requestBodies, responseBodies []string
+	for _, id := range []string{"hook_1", "hook_2"} {
+		client.Hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event RequestHookEvent) error {
+				body, err := io.ReadAll(event.RequestBody)
+				require.NoError(err)
+				requestBodies = append(requestBodies, string(body))
+				return nil
+			},
+			HookEventSubscriber: HookEventSubscriber{ID: id},
+		})
+		client.Hooks.AddResponseEventSubscriber(ResponseHookEventSubscriber{
+			Callback: func(ctx context.Context, event ResponseHookEvent) error {
+				body, err := io.ReadAll(event.ResponseBody)
+				require.NoError(err)
+				responseBodies = append(responseBodies, string(body))
+				return nil
+			},
+			HookEventSubscriber: HookEventSubscriber{ID: id},
+		})
+	}
+
+	address, err := client.CreateAddress(&Address{Street1: "417 Montgomery Street"}, nil)
+	require.NoError(err)
+
+	// reading the bodies in the hooks affects neither the request sent nor the response decoded
+	assert.Equal("adr_123", address.ID)
+	assert.Contains(string(sentBody), "417 Montgomery Street")
+	assert.Equal([]string{string(sentBody), string(sentBody)}, requestBodies)
+	assert.Equal([]string{`{"id": "adr_123", "object": "Address"}`, `{"id": "adr_123", "object": "Address"}`}, responseBodies)
+}
+
+func (c *ClientTests) TestHooksBodySizeCap() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(200, `{"id": "adr_123", "object": "Address"}`, nil))
+	client.Hooks.MaxBodyBytes = 8
+
+	var responseBody string
+	var truncated bool
+	client.Hooks.AddResponseEventSubscriber(ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event ResponseHookEvent) error {
+			body, _ := io.ReadAll(event.ResponseBody)
+			responseBody, truncated = string(body), event.ResponseBodyTruncated
+			return nil
+		},
+	})
+
+	address, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal("adr_123", address.ID)
+	assert.Equal(`{"id": "`, responseBody)
+	assert.True(truncated)
+}
+
+func (c *ClientTests) TestHooksAbortOnRequestHookError() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{}`, nil))
+	client.CircuitBreaker = NewCircuitBreaker(1, time.Minute)
+
+	vetoErr := errors.New("shipments to this country are not allowed")
+	responseHookCalled := false
+	client.Hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event RequestHookEvent) error {
+			return vetoErr
+		},
+		HookEventSubscriber: HookEventSubscriber{ID: "policy"},
+	})
+	client.Hooks.AddResponseEventSubscriber(ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event ResponseHookEvent) error {
+			responseHookCalled = true
+			return nil
+		},
+	})
+
+	// errors returned by hooks are ignored by default
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal(1, *calls)
+
+	client.Hooks.AbortOnRequestHookError = true
+	responseHookCalled = false
+	_, err = client.GetShipment("shp_123")
+	require.Error(err)
+
+	var requestHookError *RequestHookError
+	require.True(errors.As(err, &requestHookError))
+	assert.Equal("policy", requestHookError.SubscriberID)
+	assert.True(errors.Is(err, vetoErr))
+	assert.Equal(1, *calls)
+	assert.False(responseHookCalled)
+	assert.Equal(CircuitBreakerClosed, client.CircuitBreaker.State())
+}
+
+func (c *ClientTests) TestHooksConcurrentRegistration() {
+	require := c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		subscriber := RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event RequestHookEvent) error {
+				return nil
+			},
+			HookEventSubscriber: HookEventSubscriber{ID: fmt.Sprintf("hook_%d", i)},
+		}
+		go func() {
+			defer wg.Done()
+			client.Hooks.AddRequestEventSubscriber(subscriber)
+			client.Hooks.RemoveRequestEventSubscriber(subscriber)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.GetShipment("shp_123")
+			require.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	c.Assert().Empty(client.Hooks.RequestHookEventSubscriptions)
+}
+
+func (c *ClientTests) TestHooksCopiedByValue() {
+	assert := c.Assert()
+
+	var hooks Hooks
+	hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{HookEventSubscriber: HookEventSubscriber{ID: "first"}})
+
+	// Hooks hold no lock of their own, so they can be copied after first use
+	copied := hooks
+	copied.AddRequestEventSubscriber(RequestHookEventSubscriber{HookEventSubscriber: HookEventSubscriber{ID: "second"}})
+	assert.Len(hooks.requestSubscribers(), 1)
+	assert.Len(copied.requestSubscribers(), 2)
+}
+
 func (c *ClientTests) TestClientMakeAPICall() {
 	client := c.TestClient()
 	assert, require := c.Assert(), c.Require()
@@ -180,3 +334,162 @@ func (c *ClientTests) TestClientMakeAPICall() {
 	firstAddress := addresses[0].(map[string]interface{})
 	assert.Equal("Address", firstAddress["object"])
 }
+
+func (c *ClientTests) TestClientMakeAPICallWithContext() {
+	assert, require := c.Assert(), c.Require()
+
+	var sent *http.Request
+	var sentBody []byte
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		sentBody, _ = io.ReadAll(req.Body)
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, map[string]string{RequestIDHeader: "req_123"}), nil
+	})
+
+	params := struct {
+		Ruleset string `json:"ruleset"`
+	}{Ruleset: "ruleset_123"}
+	var out Shipment
+	metadata, err := client.MakeAPICallWithContext(context.Background(), http.MethodPost, "luma/promise", params, &out)
+	require.NoError(err)
+
+	assert.Equal("shp_123", out.ID)
+	assert.Equal("/v2/luma/promise", sent.URL.Path)
+	assert.JSONEq(`{"ruleset": "ruleset_123"}`, string(sentBody))
+	require.NotNil(metadata)
+	assert.Equal("req_123", metadata.RequestID)
+	assert.Equal(200, metadata.StatusCode)
+}
+
+func (c *ClientTests) TestClientMakeAPICallWithContextKeepsCallerMetadata() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, map[string]string{RequestIDHeader: "req_123"}))
+
+	var callerMetadata ResponseMetadata
+	ctx := ContextWithResponseMetadata(context.Background(), &callerMetadata)
+	metadata, err := client.MakeAPICallWithContext(ctx, http.MethodGet, "luma/rulesets", nil, nil)
+	require.NoError(err)
+
+	require.NotNil(metadata)
+	assert.Equal("req_123", metadata.RequestID)
+	assert.Equal("req_123", callerMetadata.RequestID)
+	assert.Equal(200, callerMetadata.StatusCode)
+}
+
+func (c *ClientTests) TestClientMakeAPICallWithContextError() {
+	assert, require := c.Assert(), c.Require()
+
+	var sent *http.Request
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return scriptedResponse(404, `{"error": {"code": "NOT_FOUND", "message": "not found"}}`, map[string]string{RequestIDHeader: "req_123"}), nil
+	})
+
+	metadata, err := client.MakeAPICallWithContext(context.Background(), http.MethodGet, "luma/rulesets", map[string]interface{}{"page_size": 1}, nil)
+	require.Error(err)
+
+	var notFoundError *NotFoundError
+	assert.True(errors.As(err, &notFoundError))
+	assert.Equal("1", sent.URL.Query().Get("page_size"))
+	require.NotNil(metadata)
+	assert.Equal("req_123", metadata.RequestID)
+
+	client, _ = c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	metadata, err = client.MakeAPICallWithContext(context.Background(), http.MethodGet, "luma/rulesets", nil, nil)
+	require.Error(err)
+	assert.Nil(metadata)
+}
+
+func (c *ClientTests) TestClientDoesNotModifyHTTPClient() {
+	assert, require := c.Assert(), c.Require()
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return scriptedResponse(200, `{}`, nil), nil
+	})}
+	client := &Client{APIKey: "cannot_be_blank", Client: httpClient, Timeout: 1000}
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal(time.Duration(0), httpClient.Timeout)
+	assert.Equal(time.Duration(0), http.DefaultClient.Timeout)
+	assert.Same(httpClient, client.client())
+}
+
+func (c *ClientTests) TestClientOwnsDefaultHTTPClient() {
+	assert := c.Assert()
+
+	client := &Client{APIKey: "cannot_be_blank", ConnectTimeout: 5000, ResponseHeaderTimeout: 10000}
+
+	httpClient := client.client()
+	assert.NotSame(http.DefaultClient, httpClient)
+	assert.Same(httpClient, client.client())
+	assert.Equal(time.Duration(0), httpClient.Timeout)
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	c.Require().True(ok)
+	assert.NotSame(http.DefaultTransport, transport)
+	assert.Equal(10*time.Second, transport.ResponseHeaderTimeout)
+	assert.NotNil(transport.DialContext)
+	assert.NotNil(transport.Proxy)
+
+	// each Client owns its own HTTP client
+	assert.NotSame(httpClient, New("cannot_be_blank").client())
+
+	// but Clients with the same transport settings share a transport and its connections
+	other := &Client{APIKey: "other_key", ConnectTimeout: 5000, ResponseHeaderTimeout: 10000}
+	assert.NotSame(httpClient, other.client())
+	assert.Same(transport, other.client().Transport)
+	other.ResponseHeaderTimeout = 20000
+	other.internals = nil
+	assert.NotSame(transport, other.client().Transport)
+}
+
+func (c *ClientTests) TestClientPerRequestTimeout() {
+	assert, require := c.Assert(), c.Require()
+
+	var deadlines []time.Time
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		deadline, _ := req.Context().Deadline()
+		deadlines = append(deadlines, deadline)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	client.Timeout = 20
+
+	start := time.Now()
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.True(time.Since(start) < time.Second)
+
+	// the deadline is applied to each attempt through the request context
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	deadlines = nil
+	_, err = client.GetAddress("adr_123")
+	require.Error(err)
+	require.Equal(2, len(deadlines))
+	assert.True(deadlines[1].After(deadlines[0]))
+}
+
+func (c *ClientTests) TestClientCopiedByValue() {
+	assert, require := c.Assert(), c.Require()
+
+	client := c.MockClient([]MockRequest{{
+		MatchRule:    MockRequestMatchRule{Method: "GET", UrlRegexPattern: "v2/addresses/adr_123"},
+		ResponseInfo: MockRequestResponseInfo{StatusCode: 200, Body: `{"id": "adr_123"}`},
+	}})
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	// a Client holds no lock of its own, so it can be copied after first use, e.g. to change a setting
+	copied := *client
+	copied.UserAgent = "copy/1.0"
+	address, err := copied.GetAddress("adr_123")
+	require.NoError(err)
+	assert.Equal("adr_123", address.ID)
+	assert.Len(client.MockCalls(), 2)
+}
@@ -17,6 +17,8 @@ func getCarrierAccountTypesWithCustomOauth() []string {
 
 var ApiDidNotReturnErrorDetails = "API did not return error details"
 var ApiErrorDetailsParsingError = "RESPONSE.PARSE_ERROR"
+var CircuitBreakerIsOpen = "Circuit breaker is open, the API call was not attempted"
+var IdempotencyKeyReused = "The idempotency key was already used for another API call: "
 var InvalidParameter = "Invalid parameter: "
 var JsonDeserializationErrorMessage = "Error deserializing JSON into object of type "
 var JsonNoDataErrorMessage = "No data was provided to serialize"
@@ -31,3 +33,5 @@ var NoPaymentMethods = "No payment methods are set up. Please add a payment meth
 var NoRatesFoundMatchingFilters = "No rates found matching the given filters"
 var NoUserFoundForId = "No user found for the given ID"
 var PaymentMethodNotSetUp = "The chosen payment method is not set up yet"
+var PurchaseRefusedInProductionMode = "Safe mode refused a purchase in production mode: "
+var RequestHookAbortedCall = "A request hook aborted the API call: "
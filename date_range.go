@@ -0,0 +1,134 @@
+package easypost
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDateRangeWindowSize = 24 * time.Hour
+	defaultDateRangeWorkers    = 4
+)
+
+// DateRangeOptions configures how the *InDateRange functions (e.g. ListShipmentsInDateRange) split a date range into
+// windows that are paged through concurrently.
+type DateRangeOptions struct {
+	// WindowSize is the duration of each window. If zero, a default of 24 hours will be used.
+	WindowSize time.Duration
+	// Workers is the maximum number of windows paged through at the same time. If zero, a default of 4 will be used.
+	Workers int
+}
+
+// dateWindow is a window of a date range.
+type dateWindow struct {
+	start, end time.Time
+}
+
+// splitDateRange splits the range between start and end into windows of the given size, newest first to match the
+// order the API lists objects in. The last window is shorter when the range is not a multiple of the size.
+func splitDateRange(start, end time.Time, size time.Duration) []dateWindow {
+	var windows []dateWindow
+	for windowEnd := end; windowEnd.After(start); windowEnd = windowEnd.Add(-size) {
+		windowStart := windowEnd.Add(-size)
+		if windowStart.Before(start) {
+			windowStart = start
+		}
+		windows = append(windows, dateWindow{start: windowStart, end: windowEnd})
+	}
+	return windows
+}
+
+// fetchDateRange splits the range between start and end into windows, calls fetch for each of them from a bounded
+// number of goroutines and merges their items in window order, dropping items already returned by another window. The
+// first error stops the remaining windows.
+func fetchDateRange(ctx context.Context, start, end *DateTime, options *DateRangeOptions, fetch func(ctx context.Context, start, end *DateTime) ([]interface{}, error), idOf func(item interface{}) string) ([]interface{}, error) {
+	if start == nil {
+		return nil, newMissingPropertyError("StartDateTime")
+	}
+	if end == nil {
+		return nil, newMissingPropertyError("EndDateTime")
+	}
+	if !time.Time(*end).After(time.Time(*start)) {
+		return nil, newInvalidObjectError(InvalidParameter + "EndDateTime must be after StartDateTime")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if options == nil {
+		options = &DateRangeOptions{}
+	}
+	size := options.WindowSize
+	if size <= 0 {
+		size = defaultDateRangeWindowSize
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = defaultDateRangeWorkers
+	}
+
+	windows := splitDateRange(time.Time(*start), time.Time(*end), size)
+	if workers > len(windows) {
+		workers = len(windows)
+	}
+	results := make([][]interface{}, len(windows))
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var fetchErr error
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				windowStart, windowEnd := DateTime(windows[i].start), DateTime(windows[i].end)
+				items, err := fetch(workerCtx, &windowStart, &windowEnd)
+				if err != nil {
+					errOnce.Do(func() {
+						fetchErr = err
+						cancel()
+					})
+					continue
+				}
+				results[i] = items
+			}
+		}()
+	}
+
+send:
+	for i := range windows {
+		select {
+		case jobs <- i:
+		case <-workerCtx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var merged []interface{}
+	seen := map[string]bool{}
+	for _, items := range results {
+		for _, item := range items {
+			if id := idOf(item); id != "" {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+			}
+			merged = append(merged, item)
+		}
+	}
+	return merged, nil
+}
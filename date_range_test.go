@@ -0,0 +1,176 @@
+package easypost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// listServer answers list requests for the given objects, which are sorted newest first, honoring the
+// start_datetime, end_datetime, before_id and page_size query parameters like the API does.
+func listServer(collection string, objects []map[string]interface{}) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		start, _ := time.Parse(time.RFC3339, query.Get("start_datetime"))
+		end, _ := time.Parse(time.RFC3339, query.Get("end_datetime"))
+		pageSize, _ := strconv.Atoi(query.Get("page_size"))
+		if pageSize == 0 {
+			pageSize = 20
+		}
+
+		var matching []map[string]interface{}
+		before := query.Get("before_id") != ""
+		for _, object := range objects {
+			if before {
+				if object["id"] == query.Get("before_id") {
+					before = false
+				}
+				continue
+			}
+			createdAt, _ := time.Parse(time.RFC3339, object["created_at"].(string))
+			if (!start.IsZero() && createdAt.Before(start)) || (!end.IsZero() && createdAt.After(end)) {
+				continue
+			}
+			matching = append(matching, object)
+		}
+
+		hasMore := len(matching) > pageSize
+		if hasMore {
+			matching = matching[:pageSize]
+		}
+		body, _ := json.Marshal(map[string]interface{}{collection: matching, "has_more": hasMore})
+		return scriptedResponse(200, string(body), nil), nil
+	}
+}
+
+func (c *ClientTests) TestListShipmentsInDateRange() {
+	assert, require := c.Assert(), c.Require()
+
+	origin := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var objects []map[string]interface{}
+	var expected []string
+	for i := 71; i >= 0; i-- {
+		id := fmt.Sprintf("shp_%d", i)
+		objects = append(objects, map[string]interface{}{
+			"id":         id,
+			"created_at": origin.Add(time.Duration(i)*time.Hour + 30*time.Minute).Format(time.RFC3339),
+		})
+		expected = append(expected, id)
+	}
+
+	var mu sync.Mutex
+	var purchased []string
+	server := listServer("shipments", objects)
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		purchased = append(purchased, req.URL.Query().Get("purchased"))
+		mu.Unlock()
+		return server(req)
+	})
+
+	start, end := DateTime(origin), DateTime(origin.Add(72*time.Hour))
+	shipments, err := client.ListShipmentsInDateRange(&ListShipmentsOptions{
+		StartDateTime: &start,
+		EndDateTime:   &end,
+		PageSize:      5,
+		Purchased:     BoolPtr(false),
+	}, &DateRangeOptions{WindowSize: 12 * time.Hour, Workers: 3})
+	require.NoError(err)
+
+	ids := make([]string, len(shipments))
+	for i, shipment := range shipments {
+		ids[i] = shipment.ID
+	}
+	assert.Equal(expected, ids)
+	for _, value := range purchased {
+		assert.Equal("false", value)
+	}
+}
+
+func (c *ClientTests) TestListInDateRangeKeepsFiltersOnEveryPage() {
+	assert, require := c.Assert(), c.Require()
+
+	origin := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var objects []map[string]interface{}
+	for i := 5; i >= 0; i-- {
+		objects = append(objects, map[string]interface{}{
+			"id":         fmt.Sprintf("trk_%d", i),
+			"created_at": origin.Add(time.Duration(i)*time.Hour + 30*time.Minute).Format(time.RFC3339),
+		})
+	}
+
+	var mu sync.Mutex
+	var queries []url.Values
+	server := listServer("trackers", objects)
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		queries = append(queries, req.URL.Query())
+		mu.Unlock()
+		return server(req)
+	})
+
+	start, end := DateTime(origin), DateTime(origin.Add(6*time.Hour))
+	trackers, err := client.ListTrackersInDateRange(&ListTrackersOptions{
+		StartDateTime: &start,
+		EndDateTime:   &end,
+		PageSize:      2,
+		TrackingCodes: []string{"A", "B"},
+		Carrier:       "USPS",
+	}, &DateRangeOptions{WindowSize: 3 * time.Hour, Workers: 2})
+	require.NoError(err)
+	assert.Len(trackers, 6)
+
+	// each window of 3 trackers takes two pages, and the second one has the same filters as the first
+	require.Len(queries, 4)
+	pages := 0
+	for _, query := range queries {
+		assert.Equal([]string{"A", "B"}, query["tracking_codes"])
+		assert.Equal("USPS", query.Get("carrier"))
+		assert.Equal("2", query.Get("page_size"))
+		assert.NotEmpty(query.Get("start_datetime"))
+		if query.Get("before_id") != "" {
+			pages++
+		}
+	}
+	assert.Equal(2, pages)
+}
+
+func (c *ClientTests) TestListInDateRangeStopsOnError() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(500, `{"error": {"code": "INTERNAL_SERVER_ERROR", "message": "oops"}}`, nil))
+
+	start := DateTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := DateTime(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC))
+	_, err := client.ListTrackersInDateRange(&ListTrackersOptions{StartDateTime: &start, EndDateTime: &end}, nil)
+	require.Error(err)
+
+	var internalServerError *InternalServerError
+	assert.True(errors.As(err, &internalServerError))
+
+	_, err = client.ListInsurancesInDateRange(&ListOptions{StartDateTime: &start}, nil)
+	var missingPropertyError *MissingPropertyError
+	assert.True(errors.As(err, &missingPropertyError))
+
+	_, err = client.ListInsurancesInDateRange(&ListOptions{StartDateTime: &end, EndDateTime: &start}, nil)
+	var invalidObjectError *InvalidObjectError
+	assert.True(errors.As(err, &invalidObjectError))
+}
+
+func (c *ClientTests) TestSplitDateRange() {
+	assert := c.Assert()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := splitDateRange(start, start.Add(30*time.Hour), 12*time.Hour)
+
+	assert.Equal([]dateWindow{
+		{start: start.Add(18 * time.Hour), end: start.Add(30 * time.Hour)},
+		{start: start.Add(6 * time.Hour), end: start.Add(18 * time.Hour)},
+		{start: start, end: start.Add(6 * time.Hour)},
+	}, windows)
+}
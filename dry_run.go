@@ -0,0 +1,277 @@
+package easypost
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModeDryRun is the mode of the synthetic objects returned by a Client in dry-run mode (see DryRun).
+const ModeDryRun Mode = "dry_run"
+
+// dryRunIDMarker is inserted in the IDs of synthetic objects, after the object type prefix (e.g. "shp_dryrun_...").
+const dryRunIDMarker = "_dryrun_"
+
+// DryRun captures the mutating API calls (POST, PATCH, PUT and DELETE) of a Client instead of sending them, and
+// answers them with synthetic objects. GET calls are sent to the API as usual. A DryRun is safe for concurrent use.
+//
+// Synthetic objects can never be mistaken for real ones: their Mode is ModeDryRun and their ID contains "_dryrun_"
+// (see IsDryRunID).
+type DryRun struct {
+	mu    sync.Mutex
+	calls []DryRunCall
+}
+
+// DryRunCall is a mutating API call captured by a DryRun.
+type DryRunCall struct {
+	// Time is the time the call was captured at.
+	Time time.Time `json:"time"`
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+	// Path is the path of the request URL.
+	Path string `json:"path"`
+	// Query is the encoded query string of the request URL, if any.
+	Query string `json:"query,omitempty"`
+	// RequestBody is the JSON body of the request, if any.
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	// ResponseBody is the synthetic object the call was answered with.
+	ResponseBody json.RawMessage `json:"response_body"`
+}
+
+// NewDryRun returns a new, empty DryRun.
+func NewDryRun() *DryRun {
+	return &DryRun{}
+}
+
+// Calls returns the calls captured so far, in order.
+func (d *DryRun) Calls() []DryRunCall {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DryRunCall(nil), d.calls...)
+}
+
+// Reset forgets the captured calls.
+func (d *DryRun) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = nil
+}
+
+// WriteJSONL writes the captured calls to w as JSON Lines, one DryRunCall per line.
+func (d *DryRun) WriteJSONL(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, call := range d.Calls() {
+		if err := encoder.Encode(call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DryRun) record(call DryRunCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+// IsDryRunID returns true if id is the ID of a synthetic object returned by a Client in dry-run mode.
+func IsDryRunID(id string) bool {
+	return strings.Contains(id, dryRunIDMarker)
+}
+
+// DryRunMiddleware returns a Middleware that answers the mutating requests with synthetic objects recorded in dryRun,
+// without calling the next Handler. GET requests are passed to the next Handler.
+func DryRunMiddleware(dryRun *DryRun) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				return next(req)
+			}
+
+			requestBody, err := bufferRequestBody(req)
+			if err != nil {
+				return nil, err
+			}
+			responseBody, err := json.Marshal(newDryRunObject(req.Method, req.URL.Path, requestBody))
+			if err != nil {
+				return nil, err
+			}
+
+			call := DryRunCall{
+				Time:         time.Now(),
+				Method:       req.Method,
+				Path:         req.URL.Path,
+				Query:        req.URL.RawQuery,
+				ResponseBody: responseBody,
+			}
+			if json.Valid(requestBody) {
+				call.RequestBody = requestBody
+			}
+			dryRun.record(call)
+
+			return &http.Response{
+				Status:        http.StatusText(http.StatusOK),
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          io.NopCloser(bytes.NewReader(responseBody)),
+				ContentLength: int64(len(responseBody)),
+				Request:       req,
+			}, nil
+		}
+	}
+}
+
+// dryRunResource describes the objects of an API resource.
+type dryRunResource struct {
+	object   string
+	idPrefix string
+}
+
+// dryRunResources maps the first path segment of API resources to the objects they return.
+var dryRunResources = map[string]dryRunResource{
+	"addresses":        {"Address", "adr"},
+	"batches":          {"Batch", "batch"},
+	"carrier_accounts": {"CarrierAccount", "ca"},
+	"claims":           {"Claim", "clm"},
+	"customs_infos":    {"CustomsInfo", "cstinfo"},
+	"customs_items":    {"CustomsItem", "cstitem"},
+	"end_shippers":     {"EndShipper", "es"},
+	"insurances":       {"Insurance", "ins"},
+	"orders":           {"Order", "order"},
+	"parcels":          {"Parcel", "prcl"},
+	"pickups":          {"Pickup", "pickup"},
+	"refunds":          {"Refund", "rfnd"},
+	"reports":          {"Report", "rpt"},
+	"scan_forms":       {"ScanForm", "sf"},
+	"shipments":        {"Shipment", "shp"},
+	"trackers":         {"Tracker", "trk"},
+	"users":            {"User", "user"},
+	"webhooks":         {"Webhook", "hook"},
+}
+
+// newDryRunID returns a new synthetic object ID with the given type prefix.
+func newDryRunID(prefix string) string {
+	return prefix + dryRunIDMarker + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// newDryRunObject returns the synthetic object answering a mutating request. The object echoes the parameters of the
+// request, with the resource's object type, a synthetic ID and ModeDryRun.
+func newDryRunObject(method, path string, requestBody []byte) map[string]interface{} {
+	var params map[string]interface{}
+	_ = json.Unmarshal(requestBody, &params)
+
+	resourceName, id, action := parseDryRunPath(path)
+	resource, ok := dryRunResources[resourceName]
+	if !ok {
+		resource = dryRunResource{idPrefix: "obj"}
+	}
+
+	object := map[string]interface{}{}
+	if method != http.MethodDelete && (id == "" || action == "") {
+		// creating or updating the resource, whose parameters are usually wrapped in an object named after it
+		// (e.g. {"shipment": {...}})
+		fields := params
+		if len(params) == 1 {
+			for _, value := range params {
+				if wrapped, ok := value.(map[string]interface{}); ok {
+					fields = wrapped
+				}
+			}
+		}
+		for key, value := range fields {
+			object[key] = value
+		}
+	}
+	if id == "" {
+		id = newDryRunID(resource.idPrefix)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	object["id"] = id
+	object["object"] = resource.object
+	object["mode"] = ModeDryRun
+	object["created_at"] = now
+	object["updated_at"] = now
+
+	switch {
+	case resourceName == "shipments" && action == "":
+		object["rates"] = newDryRunRates(id)
+	case resourceName == "shipments" && (action == "buy" || action == "luma"):
+		object["tracking_code"] = "DRYRUN" + strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:16])
+		object["status"] = "unknown"
+		if rate, ok := params["rate"].(map[string]interface{}); ok {
+			object["selected_rate"] = rate
+		}
+		object["postage_label"] = map[string]interface{}{
+			"id":     newDryRunID("pl"),
+			"object": "PostageLabel",
+		}
+	case resourceName == "batches" && action == "":
+		object["state"] = "created"
+		if shipments, ok := object["shipments"].([]interface{}); ok {
+			object["num_shipments"] = len(shipments)
+		}
+	}
+
+	return object
+}
+
+// newDryRunRates returns the synthetic rates of a synthetic shipment.
+func newDryRunRates(shipmentID string) []map[string]interface{} {
+	services := []struct {
+		service, rate string
+		days          int
+	}{
+		{"GroundAdvantage", "5.00", 5},
+		{"Priority", "8.00", 2},
+		{"Express", "30.00", 1},
+	}
+
+	rates := make([]map[string]interface{}, 0, len(services))
+	for _, service := range services {
+		rates = append(rates, map[string]interface{}{
+			"id":                newDryRunID("rate"),
+			"object":            "Rate",
+			"mode":              ModeDryRun,
+			"shipment_id":       shipmentID,
+			"carrier":           "USPS",
+			"service":           service.service,
+			"rate":              service.rate,
+			"currency":          "USD",
+			"delivery_days":     service.days,
+			"est_delivery_days": service.days,
+		})
+	}
+	return rates
+}
+
+// parseDryRunPath splits the path of a request URL into the name of the resource, the ID of the object and the action
+// applied to it (e.g. "/v2/shipments/shp_123/buy" is split into "shipments", "shp_123" and "buy"). The ID and action
+// are empty when the path does not contain them.
+func parseDryRunPath(path string) (resource, id, action string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if _, ok := dryRunResources[segment]; !ok {
+			continue
+		}
+		resource = segment
+		rest := segments[i+1:]
+		if len(rest) > 0 && idPathSegment.MatchString(rest[0]) {
+			id, rest = rest[0], rest[1:]
+		}
+		if len(rest) > 0 {
+			action = rest[len(rest)-1]
+		}
+		return resource, id, action
+	}
+
+	if len(segments) > 0 {
+		resource = segments[len(segments)-1]
+	}
+	return resource, "", ""
+}
@@ -0,0 +1,100 @@
+package easypost
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+func (c *ClientTests) TestDryRunAnswersMutatingCalls() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{"id": "shp_123", "object": "Shipment", "mode": "test"}`, nil))
+	client.DryRun = NewDryRun()
+
+	// GET calls go through
+	shipment, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal("shp_123", shipment.ID)
+	assert.Equal(1, *calls)
+
+	shipment, err = client.CreateShipment(&Shipment{
+		Reference: "order-1",
+		ToAddress: &Address{Street1: "417 Montgomery Street"},
+	})
+	require.NoError(err)
+	assert.True(IsDryRunID(shipment.ID))
+	assert.Equal(string(ModeDryRun), shipment.Mode)
+	assert.Equal("Shipment", shipment.Object)
+	assert.Equal("order-1", shipment.Reference)
+	assert.Equal("417 Montgomery Street", shipment.ToAddress.Street1)
+	require.NotEmpty(shipment.Rates)
+	for _, rate := range shipment.Rates {
+		assert.True(IsDryRunID(rate.ID))
+		assert.Equal(shipment.ID, rate.ShipmentID)
+	}
+
+	bought, err := client.BuyShipment(shipment.ID, shipment.Rates[0], "")
+	require.NoError(err)
+	assert.Equal(shipment.ID, bought.ID)
+	assert.Equal(shipment.Rates[0].ID, bought.SelectedRate.ID)
+	assert.NotEmpty(bought.TrackingCode)
+
+	batch, err := client.CreateBatch(shipment)
+	require.NoError(err)
+	assert.True(IsDryRunID(batch.ID))
+	assert.Equal("created", batch.State)
+	assert.Equal(1, batch.NumShipments)
+
+	// nothing but the GET call was sent
+	assert.Equal(1, *calls)
+	dryRunCalls := client.DryRun.Calls()
+	require.Len(dryRunCalls, 3)
+	assert.Equal(http.MethodPost, dryRunCalls[0].Method)
+	assert.Equal("/v2/shipments", dryRunCalls[0].Path)
+	assert.Equal("/v2/shipments/"+shipment.ID+"/buy", dryRunCalls[1].Path)
+	assert.Equal("/v2/batches", dryRunCalls[2].Path)
+}
+
+func (c *ClientTests) TestDryRunWriteJSONL() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(500, `{}`, nil))
+	client.DryRun = NewDryRun()
+
+	_, err := client.CreateParcel(&Parcel{Weight: 10})
+	require.NoError(err)
+	require.NoError(client.DeleteWebhook("hook_123"))
+
+	var buf bytes.Buffer
+	require.NoError(client.DryRun.WriteJSONL(&buf))
+
+	var lines []DryRunCall
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var call DryRunCall
+		require.NoError(json.Unmarshal(scanner.Bytes(), &call))
+		lines = append(lines, call)
+	}
+	require.Len(lines, 2)
+	assert.Equal("/v2/parcels", lines[0].Path)
+	assert.JSONEq(`{"parcel": {"weight": 10}}`, string(lines[0].RequestBody))
+	assert.Equal(http.MethodDelete, lines[1].Method)
+	assert.Equal("/v2/webhooks/hook_123", lines[1].Path)
+
+	client.DryRun.Reset()
+	assert.Empty(client.DryRun.Calls())
+}
+
+func (c *ClientTests) TestDryRunBypassesSafeMode() {
+	require := c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(500, `{}`, nil))
+	client.APIKey = "EZAK123"
+	client.SafeMode = true
+	client.DryRun = NewDryRun()
+
+	_, err := client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+}
@@ -0,0 +1,409 @@
+// Package easyposttest provides an in-memory fake of the EasyPost API for integration tests.
+//
+// A Server emulates the core of the API with state: addresses, parcels, shipments with generated rates, buying
+// (which assigns a tracking code, a PostageLabel and a tracker) and refunding shipments, trackers with a simulated
+// status progression, batches, webhooks and events. Events are delivered to the webhooks registered on the server.
+//
+//	server := easyposttest.NewServer()
+//	defer server.Close()
+//
+//	client := server.Client()
+//	shipment, err := client.CreateShipment(&easypost.Shipment{...})
+//
+// Tests can inspect the state of the server (e.g. Shipment or Events), seed it with Seed and move trackers along
+// with AdvanceTracker. Endpoints the server does not emulate answer with a 404 status code.
+package easyposttest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EasyPost/easypost-go/v5"
+	"github.com/google/uuid"
+)
+
+// APIKey is the API key of the clients returned by Server.Client. It is a test API key, so that their Mode is
+// easypost.ModeTest, like the objects of the server.
+const APIKey = "EZTK_easyposttest"
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Server is a fake EasyPost API, running on a local HTTP server until Close is called. It is safe for concurrent use.
+type Server struct {
+	server        *httptest.Server
+	webhookClient *http.Client
+
+	mu             sync.Mutex
+	objects        map[string]interface{}
+	ids            map[string][]string
+	webhookSecrets map[string]string
+	payloads       map[string][]*easypost.EventPayload
+	pendingEvents  []*pendingEvent
+	trackingCodes  int
+}
+
+// NewServer starts and returns a new Server with an empty state. The caller should call Close when finished, to shut
+// it down.
+func NewServer() *Server {
+	s := &Server{
+		webhookClient:  &http.Client{Timeout: 10 * time.Second},
+		objects:        map[string]interface{}{},
+		ids:            map[string][]string{},
+		webhookSecrets: map[string]string{},
+		payloads:       map[string][]*easypost.EventPayload{},
+	}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on it have completed.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the API emulated by the server, to be used as the BaseURL of an easypost.Client.
+func (s *Server) URL() *url.URL {
+	u, _ := url.Parse(s.server.URL + "/v2/")
+	return u
+}
+
+// Client returns a new easypost.Client pointed at the server, with APIKey as its API key.
+func (s *Server) Client() *easypost.Client {
+	return &easypost.Client{
+		APIKey:  APIKey,
+		BaseURL: s.URL(),
+		Client:  s.server.Client(),
+	}
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func newNotFoundError() *apiError {
+	return &apiError{status: http.StatusNotFound, code: "NOT_FOUND", message: "The requested resource could not be found."}
+}
+
+func newInvalidRequestError(code, message string) *apiError {
+	return &apiError{status: http.StatusUnprocessableEntity, code: code, message: message}
+}
+
+// request is a request to the API, with the IDs found in its path.
+type request struct {
+	*http.Request
+	ids  []string
+	body []byte
+}
+
+// decode decodes the JSON body of the request into v.
+func (r *request) decode(v interface{}) error {
+	if len(r.body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return &apiError{status: http.StatusBadRequest, code: "BAD_REQUEST", message: "The request body could not be parsed: " + err.Error()}
+	}
+	return nil
+}
+
+// route is an endpoint of the API. The segments of its pattern starting with a colon match any ID.
+type route struct {
+	method  string
+	pattern string
+	status  int
+	handle  func(s *Server, req *request) (interface{}, error)
+}
+
+var routes []route
+
+func init() {
+	routes = []route{
+		{http.MethodPost, "addresses", http.StatusCreated, (*Server).createAddress},
+		{http.MethodGet, "addresses", http.StatusOK, listObjects("addresses", nil)},
+		{http.MethodGet, "addresses/:id", http.StatusOK, getObject("addresses")},
+		{http.MethodPost, "parcels", http.StatusCreated, (*Server).createParcel},
+		{http.MethodGet, "parcels/:id", http.StatusOK, getObject("parcels")},
+		{http.MethodPost, "shipments", http.StatusCreated, (*Server).createShipment},
+		{http.MethodGet, "shipments", http.StatusOK, listObjects("shipments", filterShipment)},
+		{http.MethodGet, "shipments/:id", http.StatusOK, getObject("shipments")},
+		{http.MethodPost, "shipments/:id/buy", http.StatusOK, (*Server).buyShipment},
+		{http.MethodPost, "shipments/:id/refund", http.StatusOK, (*Server).refundShipment},
+		{http.MethodPost, "shipments/:id/rerate", http.StatusOK, (*Server).rerateShipment},
+		{http.MethodPost, "trackers", http.StatusCreated, (*Server).createTracker},
+		{http.MethodGet, "trackers", http.StatusOK, listObjects("trackers", filterTracker)},
+		{http.MethodGet, "trackers/:id", http.StatusOK, getObject("trackers")},
+		{http.MethodDelete, "trackers/:id", http.StatusOK, deleteObject("trackers")},
+		{http.MethodPost, "batches", http.StatusCreated, (*Server).createBatch},
+		{http.MethodGet, "batches", http.StatusOK, listObjects("batches", nil)},
+		{http.MethodGet, "batches/:id", http.StatusOK, getObject("batches")},
+		{http.MethodPost, "batches/:id/add_shipments", http.StatusOK, (*Server).addShipmentsToBatch},
+		{http.MethodPost, "batches/:id/remove_shipments", http.StatusOK, (*Server).removeShipmentsFromBatch},
+		{http.MethodPost, "batches/:id/buy", http.StatusOK, (*Server).buyBatch},
+		{http.MethodPost, "webhooks", http.StatusCreated, (*Server).createWebhook},
+		{http.MethodGet, "webhooks", http.StatusOK, (*Server).listWebhooks},
+		{http.MethodGet, "webhooks/:id", http.StatusOK, getObject("webhooks")},
+		{http.MethodPatch, "webhooks/:id", http.StatusOK, (*Server).updateWebhook},
+		{http.MethodDelete, "webhooks/:id", http.StatusOK, deleteObject("webhooks")},
+		{http.MethodGet, "events", http.StatusOK, listObjects("events", nil)},
+		{http.MethodGet, "events/:id", http.StatusOK, getObject("events")},
+		{http.MethodGet, "events/:id/payloads", http.StatusOK, (*Server).listEventPayloads},
+		{http.MethodGet, "events/:id/payloads/:id", http.StatusOK, (*Server).getEventPayload},
+	}
+}
+
+// match returns the IDs found in the path if it matches the pattern of the route.
+func (rt *route) match(method, path string) ([]string, bool) {
+	if method != rt.method {
+		return nil, false
+	}
+	segments, patternSegments := strings.Split(path, "/"), strings.Split(rt.pattern, "/")
+	if len(segments) != len(patternSegments) {
+		return nil, false
+	}
+	var ids []string
+	for i, segment := range patternSegments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			ids = append(ids, segments[i])
+		case segment != segments[i]:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// ServeHTTP answers a request to the API. Events created while answering it are delivered to the webhooks before
+// the response is written, so that they have been received once the API call returns.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if apiKey, _, ok := r.BasicAuth(); !ok || apiKey == "" {
+		writeError(w, &apiError{status: http.StatusUnauthorized, code: "APIKEY.REQUIRED", message: "No API key was provided."})
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &apiError{status: http.StatusBadRequest, code: "BAD_REQUEST", message: err.Error()})
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+	for _, rt := range routes {
+		ids, ok := rt.match(r.Method, path)
+		if !ok {
+			continue
+		}
+
+		s.mu.Lock()
+		res, err := rt.handle(s, &request{Request: r, ids: ids, body: body})
+		var encoded []byte
+		if err == nil {
+			// the response is encoded while the state is locked, as it may be modified by the next request
+			encoded, err = json.Marshal(res)
+		}
+		events := s.takePendingEvents()
+		s.mu.Unlock()
+
+		s.deliver(events)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rt.status)
+		_, _ = w.Write(encoded)
+		return
+	}
+	writeError(w, newNotFoundError())
+}
+
+// writeError writes an error response in the format of the API.
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		e = &apiError{status: http.StatusInternalServerError, code: "INTERNAL_SERVER_ERROR", message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"code": e.code, "message": e.message, "errors": []interface{}{}},
+	})
+}
+
+// newID returns a new object ID with the given prefix, e.g. "shp".
+func newID(prefix string) string {
+	return prefix + "_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// now returns the current time, to the second like the API.
+func now() *easypost.DateTime {
+	dt := easypost.DateTimeFromTime(time.Now().UTC().Truncate(time.Second))
+	return &dt
+}
+
+// put stores an object of a collection, replacing any object with the same ID.
+func (s *Server) put(collection, id string, object interface{}) {
+	if _, ok := s.objects[id]; !ok {
+		s.ids[collection] = append(s.ids[collection], id)
+	}
+	s.objects[id] = object
+}
+
+// find returns the object of a collection with the given ID.
+func (s *Server) find(collection, id string) (interface{}, error) {
+	if object, ok := s.objects[id]; ok {
+		for _, collectionID := range s.ids[collection] {
+			if collectionID == id {
+				return object, nil
+			}
+		}
+	}
+	return nil, newNotFoundError()
+}
+
+// remove deletes the object of a collection with the given ID.
+func (s *Server) remove(collection, id string) error {
+	if _, err := s.find(collection, id); err != nil {
+		return err
+	}
+	delete(s.objects, id)
+	ids := s.ids[collection]
+	for i, collectionID := range ids {
+		if collectionID == id {
+			s.ids[collection] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// getObject returns a handler retrieving an object of a collection by ID.
+func getObject(collection string) func(s *Server, req *request) (interface{}, error) {
+	return func(s *Server, req *request) (interface{}, error) {
+		return s.find(collection, req.ids[0])
+	}
+}
+
+// deleteObject returns a handler deleting an object of a collection by ID.
+func deleteObject(collection string) func(s *Server, req *request) (interface{}, error) {
+	return func(s *Server, req *request) (interface{}, error) {
+		return map[string]interface{}{}, s.remove(collection, req.ids[0])
+	}
+}
+
+// listObjects returns a handler listing a collection newest first, one page at a time, honoring the before_id,
+// after_id, page_size, start_datetime and end_datetime query parameters. filter, if set, drops the objects that do
+// not match the other query parameters.
+func listObjects(collection string, filter func(object interface{}, query url.Values) bool) func(s *Server, req *request) (interface{}, error) {
+	return func(s *Server, req *request) (interface{}, error) {
+		query := req.URL.Query()
+		pageSize := defaultPageSize
+		if value := query.Get("page_size"); value != "" {
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 1 || size > maxPageSize {
+				return nil, newInvalidRequestError("PARAMETER.INVALID", "page_size must be between 1 and "+strconv.Itoa(maxPageSize))
+			}
+			pageSize = size
+		}
+		start, end, err := dateWindow(query)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := s.ids[collection]
+		newestFirst := make([]string, len(ids))
+		for i, id := range ids {
+			newestFirst[len(ids)-1-i] = id
+		}
+		if beforeID := query.Get("before_id"); beforeID != "" {
+			newestFirst = idsAfter(newestFirst, beforeID)
+		} else if afterID := query.Get("after_id"); afterID != "" {
+			// after_id pages towards newer objects, which are still returned newest first
+			olderFirst := idsAfter(ids, afterID)
+			newestFirst = make([]string, len(olderFirst))
+			for i, id := range olderFirst {
+				newestFirst[len(olderFirst)-1-i] = id
+			}
+		}
+
+		items := []interface{}{}
+		hasMore := false
+		for _, id := range newestFirst {
+			object := s.objects[id]
+			if createdAt := createdAtOf(object); createdAt != nil {
+				t := time.Time(*createdAt)
+				if (!start.IsZero() && t.Before(start)) || (!end.IsZero() && t.After(end)) {
+					continue
+				}
+			}
+			if filter != nil && !filter(object, query) {
+				continue
+			}
+			if len(items) == pageSize {
+				hasMore = true
+				break
+			}
+			items = append(items, object)
+		}
+		return map[string]interface{}{collection: items, "has_more": hasMore}, nil
+	}
+}
+
+// idsAfter returns the IDs following the given one, or none if it is unknown.
+func idsAfter(ids []string, id string) []string {
+	for i, candidate := range ids {
+		if candidate == id {
+			return ids[i+1:]
+		}
+	}
+	return nil
+}
+
+// dateWindow parses the start_datetime and end_datetime query parameters, which are zero when absent.
+func dateWindow(query url.Values) (start, end time.Time, err error) {
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"start_datetime", &start}, {"end_datetime", &end}} {
+		if value := query.Get(param.name); value != "" {
+			if *param.value, err = time.Parse(time.RFC3339, value); err != nil {
+				return start, end, newInvalidRequestError("PARAMETER.INVALID", param.name+" must be an RFC 3339 date")
+			}
+		}
+	}
+	return start, end, nil
+}
+
+// createdAtOf returns the creation time of an object, or nil if it has none.
+func createdAtOf(object interface{}) *easypost.DateTime {
+	switch o := object.(type) {
+	case *easypost.Address:
+		return o.CreatedAt
+	case *easypost.Parcel:
+		return o.CreatedAt
+	case *easypost.Shipment:
+		return o.CreatedAt
+	case *easypost.Tracker:
+		return o.CreatedAt
+	case *easypost.Batch:
+		return o.CreatedAt
+	case *easypost.Event:
+		return o.CreatedAt
+	}
+	return nil
+}
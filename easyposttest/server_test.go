@@ -0,0 +1,247 @@
+package easyposttest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EasyPost/easypost-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShipment(reference string) *easypost.Shipment {
+	return &easypost.Shipment{
+		Reference:   reference,
+		ToAddress:   &easypost.Address{Name: "Dr. Steve Brule", Street1: "179 N Harbor Dr", City: "Redondo Beach", State: "CA", Zip: "90277", Country: "US"},
+		FromAddress: &easypost.Address{Company: "EasyPost", Street1: "417 Montgomery Street", City: "San Francisco", State: "CA", Zip: "94104", Country: "US"},
+		Parcel:      &easypost.Parcel{Length: 10, Width: 8, Height: 4, Weight: 15.4},
+	}
+}
+
+func TestShipmentLifecycle(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	shipment, err := client.CreateShipment(newShipment("order_1"))
+	require.NoError(err)
+	assert.True(strings.HasPrefix(shipment.ID, "shp_"))
+	assert.Equal("test", shipment.Mode)
+	assert.Equal("90277", shipment.ToAddress.Zip)
+	assert.NotEmpty(shipment.ToAddress.ID)
+	require.Len(shipment.Rates, len(services))
+	assert.Equal(easypost.ModeTest, client.Mode())
+
+	rate, err := client.LowestShipmentRate(shipment)
+	require.NoError(err)
+	assert.Equal("GroundAdvantage", rate.Service)
+	assert.Equal("6.54", rate.Rate)
+
+	bought, err := client.BuyShipment(shipment.ID, &rate, "100.00")
+	require.NoError(err)
+	assert.Equal(rate.ID, bought.SelectedRate.ID)
+	assert.Equal("EZ1000000001", bought.TrackingCode)
+	require.NotNil(bought.PostageLabel)
+	assert.NotEmpty(bought.PostageLabel.LabelURL)
+	require.NotNil(bought.Tracker)
+	assert.Equal("pre_transit", bought.Tracker.Status)
+	assert.Equal(shipment.ID, bought.Tracker.ShipmentID)
+
+	_, err = client.BuyShipment(shipment.ID, &rate, "")
+	var invalidRequestError *easypost.InvalidRequestError
+	require.True(errors.As(err, &invalidRequestError))
+	assert.Equal("SHIPMENT.POSTAGE.EXISTS", invalidRequestError.Code)
+
+	// the parcel moves along, and the shipment follows its tracker
+	_, err = server.AdvanceTracker(bought.Tracker.ID)
+	require.NoError(err)
+	tracker, err := server.AdvanceTracker(bought.Tracker.ID)
+	require.NoError(err)
+	assert.Equal("out_for_delivery", tracker.Status)
+	assert.Len(tracker.TrackingDetails, 3)
+
+	retrieved, err := client.GetShipment(shipment.ID)
+	require.NoError(err)
+	assert.Equal("out_for_delivery", retrieved.Status)
+	assert.Equal("out_for_delivery", retrieved.Tracker.Status)
+
+	_, err = client.RefundShipment(shipment.ID)
+	require.True(errors.As(err, &invalidRequestError))
+
+	// a shipment that was not scanned yet can be refunded
+	other, err := client.CreateShipment(newShipment("order_2"))
+	require.NoError(err)
+	_, err = client.BuyShipment(other.ID, other.Rates[1], "")
+	require.NoError(err)
+	refunded, err := client.RefundShipment(other.ID)
+	require.NoError(err)
+	assert.Equal("submitted", refunded.RefundStatus)
+	assert.Equal("submitted", server.Shipment(other.ID).RefundStatus)
+
+	_, err = client.GetShipment("shp_unknown")
+	var notFoundError *easypost.NotFoundError
+	assert.True(errors.As(err, &notFoundError))
+}
+
+func TestListAndSeed(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	address := &easypost.Address{ID: "adr_seeded", Street1: "1 Main St", Zip: "10001", Country: "US"}
+	require.NoError(server.Seed(address, &easypost.Tracker{TrackingCode: "EZ4000000004", Carrier: "USPS"}))
+	assert.Error(server.Seed("not an object"))
+
+	// a seeded address can be referenced by ID
+	shipment := newShipment("")
+	shipment.ToAddress = &easypost.Address{ID: "adr_seeded"}
+	created, err := client.CreateShipment(shipment)
+	require.NoError(err)
+	assert.Equal("10001", created.ToAddress.Zip)
+
+	for _, code := range []string{"EZ1000000001", "EZ2000000002", "EZ3000000003"} {
+		_, err = client.CreateTracker(&easypost.CreateTrackerOptions{TrackingCode: code, Carrier: "USPS"})
+		require.NoError(err)
+	}
+	again, err := client.CreateTracker(&easypost.CreateTrackerOptions{TrackingCode: "EZ2000000002", Carrier: "USPS"})
+	require.NoError(err)
+	assert.Equal("in_transit", again.Status)
+
+	var statuses []string
+	it := client.IterateTrackers(&easypost.ListTrackersOptions{PageSize: 2}, nil)
+	for it.Next() {
+		statuses = append(statuses, it.Tracker().Status)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"out_for_delivery", "in_transit", "pre_transit", "delivered"}, statuses)
+
+	filtered, err := client.ListTrackers(&easypost.ListTrackersOptions{TrackingCode: "EZ3000000003"})
+	require.NoError(err)
+	require.Len(filtered.Trackers, 1)
+	assert.Equal("out_for_delivery", filtered.Trackers[0].Status)
+	assert.Len(server.Trackers(), 4)
+}
+
+func TestBatches(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	existing, err := client.CreateShipment(newShipment("order_1"))
+	require.NoError(err)
+
+	batch, err := client.CreateBatch(&easypost.Shipment{ID: existing.ID}, newShipment("order_2"))
+	require.NoError(err)
+	assert.Equal("created", batch.State)
+	assert.Equal(2, batch.NumShipments)
+
+	third, err := client.CreateShipment(newShipment("order_3"))
+	require.NoError(err)
+	batch, err = client.AddShipmentsToBatch(batch.ID, third)
+	require.NoError(err)
+	assert.Equal(3, batch.NumShipments)
+	batch, err = client.RemoveShipmentsFromBatch(batch.ID, existing)
+	require.NoError(err)
+	assert.Equal(2, batch.NumShipments)
+
+	batch, err = client.BuyBatch(batch.ID)
+	require.NoError(err)
+	assert.Equal("purchased", batch.State)
+	assert.Equal(2, batch.Status.PostagePurchased)
+	for _, shipment := range batch.Shipments {
+		assert.NotEmpty(shipment.TrackingCode)
+		assert.Equal("GroundAdvantage", shipment.SelectedRate.Service)
+	}
+	assert.Nil(server.Shipment(existing.ID).PostageLabel)
+	assert.Equal("purchased", server.Batch(batch.ID).State)
+
+	_, err = client.BuyBatch(batch.ID)
+	var invalidRequestError *easypost.InvalidRequestError
+	assert.True(errors.As(err, &invalidRequestError))
+}
+
+func TestWebhooksAndEvents(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := hmac.New(sha256.New, []byte("sekrit"))
+		signature.Write(body)
+		if r.Header.Get("X-Hmac-Signature") != "hmac-sha256-hex="+hex.EncodeToString(signature.Sum(nil)) || r.Header.Get("X-Team") != "ops" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event map[string]interface{}
+		_ = json.Unmarshal(body, &event)
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	webhook, err := client.CreateWebhook(&easypost.CreateUpdateWebhookOptions{
+		URL:           receiver.URL,
+		WebhookSecret: "sekrit",
+		CustomHeaders: []easypost.WebhookCustomHeader{{Name: "X-Team", Value: "ops"}},
+	})
+	require.NoError(err)
+	assert.Empty(webhook.WebhookSecret)
+	webhooks, err := client.ListWebhooks()
+	require.NoError(err)
+	assert.Len(webhooks, 1)
+
+	tracker, err := client.CreateTracker(&easypost.CreateTrackerOptions{TrackingCode: "EZ1000000001"})
+	require.NoError(err)
+	_, err = server.AdvanceTracker(tracker.ID)
+	require.NoError(err)
+
+	// events are delivered before the calls creating them return
+	mu.Lock()
+	require.Len(received, 2)
+	assert.Equal("tracker.created", received[0]["description"])
+	assert.Equal("tracker.updated", received[1]["description"])
+	assert.Equal("in_transit", received[1]["result"].(map[string]interface{})["status"])
+	mu.Unlock()
+
+	events := server.Events()
+	require.Len(events, 2)
+	assert.Equal("completed", events[1].Status)
+	assert.Equal([]string{receiver.URL}, events[1].CompletedURLs)
+	assert.Equal("pre_transit", events[0].Result.(*easypost.Tracker).Status)
+	payloads := server.EventPayloads(events[1].ID)
+	require.Len(payloads, 1)
+	assert.Equal(200, payloads[0].ResponseCode)
+
+	// deliveries rejected by the webhook fail the event
+	_, err = client.UpdateWebhook(webhook.ID, &easypost.CreateUpdateWebhookOptions{WebhookSecret: "rotated"})
+	require.NoError(err)
+	_, err = server.AdvanceTracker(tracker.ID)
+	require.NoError(err)
+	events = server.Events()
+	assert.Equal("failed", events[2].Status)
+	assert.Equal(401, server.EventPayloads(events[2].ID)[0].ResponseCode)
+
+	require.NoError(client.DeleteWebhook(webhook.ID))
+	assert.Nil(server.Webhook(webhook.ID))
+}
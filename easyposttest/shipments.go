@@ -0,0 +1,464 @@
+package easyposttest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EasyPost/easypost-go/v5"
+)
+
+// labelURLPrefix is the prefix of the URLs of the postage labels, on a reserved domain as the labels are not served.
+const labelURLPrefix = "https://easyposttest.invalid/postage_label/"
+
+// service is a carrier service rated for every shipment.
+type service struct {
+	carrier   string
+	service   string
+	base      float64
+	perOunce  float64
+	transit   int
+	guarantee bool
+}
+
+var services = []service{
+	{carrier: "USPS", service: "GroundAdvantage", base: 5.00, perOunce: 0.10, transit: 5},
+	{carrier: "USPS", service: "Priority", base: 7.50, perOunce: 0.15, transit: 2},
+	{carrier: "USPS", service: "Express", base: 27.00, perOunce: 0.25, transit: 1, guarantee: true},
+	{carrier: "UPS", service: "Ground", base: 9.00, perOunce: 0.12, transit: 4},
+	{carrier: "UPS", service: "NextDayAir", base: 35.00, perOunce: 0.30, transit: 1, guarantee: true},
+}
+
+// newRates returns the rates of a shipment, priced by the weight of its parcel.
+func newRates(shipment *easypost.Shipment) []*easypost.Rate {
+	weight := 0.0
+	if shipment.Parcel != nil {
+		weight = shipment.Parcel.Weight
+	}
+
+	createdAt := now()
+	rates := make([]*easypost.Rate, len(services))
+	for i, svc := range services {
+		price := svc.base + svc.perOunce*weight
+		deliveryDate := easypost.DateTimeFromTime(time.Time(*createdAt).AddDate(0, 0, svc.transit))
+		rates[i] = &easypost.Rate{
+			ID:                     newID("rate"),
+			Object:                 "Rate",
+			Mode:                   shipment.Mode,
+			CreatedAt:              createdAt,
+			UpdatedAt:              createdAt,
+			Service:                svc.service,
+			Carrier:                svc.carrier,
+			CarrierAccountID:       "ca_easyposttest_" + strings.ToLower(svc.carrier),
+			ShipmentID:             shipment.ID,
+			Rate:                   fmt.Sprintf("%.2f", price),
+			Currency:               "USD",
+			RetailRate:             fmt.Sprintf("%.2f", price*1.2),
+			RetailCurrency:         "USD",
+			ListRate:               fmt.Sprintf("%.2f", price*1.1),
+			ListCurrency:           "USD",
+			DeliveryDays:           svc.transit,
+			DeliveryDate:           &deliveryDate,
+			DeliveryDateGuaranteed: svc.guarantee,
+			EstDeliveryDays:        svc.transit,
+			BillingType:            "easypost",
+		}
+	}
+	return rates
+}
+
+func (s *Server) createAddress(req *request) (interface{}, error) {
+	var body struct {
+		Address *easypost.Address `json:"address"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Address == nil {
+		return nil, newInvalidRequestError("PARAMETER.REQUIRED", "Missing required parameter: address")
+	}
+	return body.Address, s.create(body.Address)
+}
+
+func (s *Server) createParcel(req *request) (interface{}, error) {
+	var body struct {
+		Parcel *easypost.Parcel `json:"parcel"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Parcel == nil {
+		return nil, newInvalidRequestError("PARAMETER.REQUIRED", "Missing required parameter: parcel")
+	}
+	return body.Parcel, s.create(body.Parcel)
+}
+
+func (s *Server) createShipment(req *request) (interface{}, error) {
+	var body struct {
+		Shipment *easypost.Shipment `json:"shipment"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Shipment == nil {
+		return nil, newInvalidRequestError("PARAMETER.REQUIRED", "Missing required parameter: shipment")
+	}
+	return body.Shipment, s.create(body.Shipment)
+}
+
+// create stores an object sent to the API, dropping the fields only the API sets. The objects nested in a shipment
+// or a batch are either references to existing objects, given by their ID, or new objects.
+func (s *Server) create(object interface{}) error {
+	switch o := object.(type) {
+	case *easypost.Address:
+		*o = easypost.Address{
+			Reference: o.Reference, Street1: o.Street1, Street2: o.Street2, City: o.City, State: o.State, Zip: o.Zip,
+			Country: o.Country, Name: o.Name, Company: o.Company, Phone: o.Phone, Email: o.Email,
+			Residential: o.Residential, CarrierFacility: o.CarrierFacility, FederalTaxID: o.FederalTaxID,
+			StateTaxID: o.StateTaxID,
+		}
+	case *easypost.Parcel:
+		*o = easypost.Parcel{
+			Length: o.Length, Width: o.Width, Height: o.Height, PredefinedPackage: o.PredefinedPackage, Weight: o.Weight,
+		}
+	case *easypost.Shipment:
+		*o = easypost.Shipment{
+			Reference: o.Reference, ToAddress: o.ToAddress, FromAddress: o.FromAddress, ReturnAddress: o.ReturnAddress,
+			BuyerAddress: o.BuyerAddress, Parcel: o.Parcel, CustomsInfo: o.CustomsInfo, Options: o.Options,
+			IsReturn: o.IsReturn, CarrierAccountIDs: o.CarrierAccountIDs, TaxIdentifiers: o.TaxIdentifiers,
+		}
+		if o.ToAddress == nil {
+			return newInvalidRequestError("SHIPMENT.INVALID_PARAMS", "Missing required parameter: to_address")
+		}
+		if o.FromAddress == nil {
+			return newInvalidRequestError("SHIPMENT.INVALID_PARAMS", "Missing required parameter: from_address")
+		}
+		if o.Parcel == nil {
+			return newInvalidRequestError("SHIPMENT.INVALID_PARAMS", "Missing required parameter: parcel")
+		}
+		for _, address := range []**easypost.Address{&o.ToAddress, &o.FromAddress, &o.ReturnAddress, &o.BuyerAddress} {
+			if err := s.resolve("addresses", address); err != nil {
+				return err
+			}
+		}
+		if err := s.resolve("parcels", &o.Parcel); err != nil {
+			return err
+		}
+	}
+	return s.store(object)
+}
+
+// resolve replaces the object field points to with the existing object of the collection it references by ID, or
+// creates it if it has no ID. Nil fields are left as is.
+func (s *Server) resolve(collection string, field interface{}) error {
+	var id string
+	var object interface{}
+	switch f := field.(type) {
+	case **easypost.Address:
+		if *f == nil {
+			return nil
+		}
+		id, object = (*f).ID, *f
+	case **easypost.Parcel:
+		if *f == nil {
+			return nil
+		}
+		id, object = (*f).ID, *f
+	case **easypost.Shipment:
+		if *f == nil {
+			return nil
+		}
+		id, object = (*f).ID, *f
+	}
+
+	if id == "" {
+		return s.create(object)
+	}
+	existing, err := s.find(collection, id)
+	if err != nil {
+		return err
+	}
+	switch f := field.(type) {
+	case **easypost.Address:
+		*f = existing.(*easypost.Address)
+	case **easypost.Parcel:
+		*f = existing.(*easypost.Parcel)
+	case **easypost.Shipment:
+		*f = existing.(*easypost.Shipment)
+	}
+	return nil
+}
+
+// findShipment returns the shipment with the given ID.
+func (s *Server) findShipment(id string) (*easypost.Shipment, error) {
+	object, err := s.find("shipments", id)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*easypost.Shipment), nil
+}
+
+func (s *Server) buyShipment(req *request) (interface{}, error) {
+	shipment, err := s.findShipment(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Rate      *easypost.Rate `json:"rate"`
+		Insurance string         `json:"insurance"`
+	}
+	if err = req.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Rate == nil {
+		return nil, newInvalidRequestError("PARAMETER.REQUIRED", "Missing required parameter: rate")
+	}
+	return shipment, s.buy(shipment, body.Rate.ID, body.Insurance)
+}
+
+// buy purchases a shipment with one of its rates: it is assigned a tracking code, a postage label and a tracker.
+func (s *Server) buy(shipment *easypost.Shipment, rateID, insurance string) error {
+	if shipment.PostageLabel != nil {
+		return newInvalidRequestError("SHIPMENT.POSTAGE.EXISTS", "Postage already exists for this shipment.")
+	}
+	var rate *easypost.Rate
+	for _, candidate := range shipment.Rates {
+		if candidate.ID == rateID {
+			rate = candidate
+		}
+	}
+	if rate == nil {
+		return newInvalidRequestError("SHIPMENT.RATE.INVALID", "The rate "+rateID+" does not belong to this shipment.")
+	}
+
+	s.trackingCodes++
+	purchasedAt := now()
+	labelID := newID("pl")
+	shipment.SelectedRate = rate
+	shipment.TrackingCode = fmt.Sprintf("EZ1%09d", s.trackingCodes)
+	shipment.PostageLabel = &easypost.PostageLabel{
+		ID:              labelID,
+		Object:          "PostageLabel",
+		CreatedAt:       purchasedAt,
+		UpdatedAt:       purchasedAt,
+		LabelDate:       purchasedAt,
+		LabelFileType:   "image/png",
+		LabelResolution: 300,
+		LabelSize:       "4x6",
+		LabelType:       "default",
+		LabelURL:        labelURLPrefix + labelID + ".png",
+	}
+	shipment.Fees = []*easypost.Fee{
+		{Object: "Fee", Type: "LabelFee", Amount: "0.00000", Charged: true},
+		{Object: "Fee", Type: "PostageFee", Amount: rate.Rate + "000", Charged: true},
+	}
+	if insurance != "" {
+		shipment.Insurance = insurance
+		shipment.Fees = append(shipment.Fees, &easypost.Fee{Object: "Fee", Type: "InsuranceFee", Amount: "0.50000", Charged: true})
+	}
+	shipment.Tracker = s.newTracker(shipment.TrackingCode, rate.Carrier, shipment.ID)
+	shipment.Status = shipment.Tracker.Status
+	shipment.UpdatedAt = purchasedAt
+	return nil
+}
+
+func (s *Server) refundShipment(req *request) (interface{}, error) {
+	shipment, err := s.findShipment(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case shipment.PostageLabel == nil:
+		return nil, newInvalidRequestError("SHIPMENT.REFUND.UNAVAILABLE", "The shipment has not been purchased.")
+	case shipment.RefundStatus != "":
+		return nil, newInvalidRequestError("SHIPMENT.REFUND.UNAVAILABLE", "A refund has already been requested for this shipment.")
+	case shipment.Tracker != nil && shipment.Tracker.Status != "pre_transit" && shipment.Tracker.Status != "unknown":
+		return nil, newInvalidRequestError("SHIPMENT.REFUND.UNAVAILABLE", "The shipment has already been scanned by the carrier.")
+	}
+	shipment.RefundStatus = "submitted"
+	shipment.UpdatedAt = now()
+	return shipment, nil
+}
+
+func (s *Server) rerateShipment(req *request) (interface{}, error) {
+	shipment, err := s.findShipment(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	shipment.Rates = newRates(shipment)
+	return map[string]interface{}{"rates": shipment.Rates}, nil
+}
+
+// filterShipment honors the purchased query parameter of the shipment list.
+func filterShipment(object interface{}, query url.Values) bool {
+	if purchased, err := strconv.ParseBool(query.Get("purchased")); err == nil {
+		return (object.(*easypost.Shipment).PostageLabel != nil) == purchased
+	}
+	return true
+}
+
+// lowestRate returns the cheapest rate of a shipment, or nil if it has none.
+func lowestRate(shipment *easypost.Shipment) *easypost.Rate {
+	var lowest *easypost.Rate
+	var lowestPrice float64
+	for _, rate := range shipment.Rates {
+		price, err := strconv.ParseFloat(rate.Rate, 64)
+		if err == nil && (lowest == nil || price < lowestPrice) {
+			lowest, lowestPrice = rate, price
+		}
+	}
+	return lowest
+}
+
+func (s *Server) createBatch(req *request) (interface{}, error) {
+	var body struct {
+		Batch *easypost.Batch `json:"batch"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	batch := &easypost.Batch{}
+	if body.Batch != nil {
+		batch.Reference = body.Batch.Reference
+		batch.Shipments = body.Batch.Shipments
+	}
+	for i := range batch.Shipments {
+		if err := s.resolve("shipments", &batch.Shipments[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.store(batch); err != nil {
+		return nil, err
+	}
+	updateBatch(batch)
+	s.emit("batch.created", batch)
+	return batch, nil
+}
+
+// findBatch returns the batch with the given ID.
+func (s *Server) findBatch(id string) (*easypost.Batch, error) {
+	object, err := s.find("batches", id)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*easypost.Batch), nil
+}
+
+// batchShipments decodes the shipments of a request adding them to or removing them from a batch.
+func (s *Server) batchShipments(req *request) ([]*easypost.Shipment, error) {
+	var body struct {
+		Shipments []*easypost.Shipment `json:"shipments"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	for i := range body.Shipments {
+		if body.Shipments[i] == nil || body.Shipments[i].ID == "" {
+			return nil, newInvalidRequestError("BATCH.SHIPMENTS.INVALID", "Shipments must be given by ID.")
+		}
+		if err := s.resolve("shipments", &body.Shipments[i]); err != nil {
+			return nil, err
+		}
+	}
+	return body.Shipments, nil
+}
+
+func (s *Server) addShipmentsToBatch(req *request) (interface{}, error) {
+	batch, err := s.findBatch(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	shipments, err := s.batchShipments(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if batchIndex(batch, shipment.ID) < 0 {
+			batch.Shipments = append(batch.Shipments, shipment)
+		}
+	}
+	updateBatch(batch)
+	return batch, nil
+}
+
+func (s *Server) removeShipmentsFromBatch(req *request) (interface{}, error) {
+	batch, err := s.findBatch(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	shipments, err := s.batchShipments(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if i := batchIndex(batch, shipment.ID); i >= 0 {
+			batch.Shipments = append(batch.Shipments[:i:i], batch.Shipments[i+1:]...)
+			shipment.BatchID, shipment.BatchStatus, shipment.BatchMessage = "", "", ""
+		}
+	}
+	updateBatch(batch)
+	return batch, nil
+}
+
+// batchIndex returns the index of a shipment in a batch, or -1 if it is not part of it.
+func batchIndex(batch *easypost.Batch, shipmentID string) int {
+	for i, shipment := range batch.Shipments {
+		if shipment.ID == shipmentID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) buyBatch(req *request) (interface{}, error) {
+	batch, err := s.findBatch(req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if batch.State != "created" {
+		return nil, newInvalidRequestError("BATCH.STATE.INVALID", "The batch cannot be purchased in the "+batch.State+" state.")
+	}
+
+	// every shipment is bought with its lowest rate
+	for _, shipment := range batch.Shipments {
+		if shipment.PostageLabel != nil {
+			shipment.BatchStatus = "postage_purchased"
+			continue
+		}
+		rateID := ""
+		if rate := lowestRate(shipment); rate != nil {
+			rateID = rate.ID
+		}
+		if err := s.buy(shipment, rateID, ""); err != nil {
+			shipment.BatchStatus, shipment.BatchMessage = "postage_purchase_failed", err.Error()
+		} else {
+			shipment.BatchStatus, shipment.BatchMessage = "postage_purchased", ""
+		}
+	}
+	batch.State = "purchased"
+	updateBatch(batch)
+	s.emit("batch.updated", batch)
+	return batch, nil
+}
+
+// updateBatch updates the counts of a batch and the batch fields of its shipments after they changed.
+func updateBatch(batch *easypost.Batch) {
+	status := &easypost.BatchStatus{}
+	for _, shipment := range batch.Shipments {
+		shipment.BatchID = batch.ID
+		switch shipment.BatchStatus {
+		case "postage_purchased":
+			status.PostagePurchased++
+		case "postage_purchase_failed":
+			status.PostagePurchaseFailed++
+		default:
+			shipment.BatchStatus = "queued_for_purchase"
+			status.QueuedForPurchase++
+		}
+	}
+	batch.NumShipments = len(batch.Shipments)
+	batch.Status = status
+	batch.UpdatedAt = now()
+}
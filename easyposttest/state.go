@@ -0,0 +1,229 @@
+package easyposttest
+
+import (
+	"fmt"
+
+	"github.com/EasyPost/easypost-go/v5"
+)
+
+// stamp sets the ID, object type and mode of an object, keeping an ID that is already set.
+func stamp(id *string, prefix string, object *string, name string, mode *string) {
+	if *id == "" {
+		*id = newID(prefix)
+	}
+	*object = name
+	if *mode == "" {
+		*mode = string(easypost.ModeTest)
+	}
+}
+
+// timestamps sets the creation and update times of an object, keeping those that are already set.
+func timestamps(createdAt, updatedAt **easypost.DateTime) {
+	if *createdAt == nil {
+		*createdAt = now()
+	}
+	if *updatedAt == nil {
+		*updatedAt = *createdAt
+	}
+}
+
+// store completes an object with the fields the API sets and stores it, along with the objects nested in it.
+func (s *Server) store(object interface{}) error {
+	switch o := object.(type) {
+	case *easypost.Address:
+		stamp(&o.ID, "adr", &o.Object, "Address", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		s.put("addresses", o.ID, o)
+	case *easypost.Parcel:
+		stamp(&o.ID, "prcl", &o.Object, "Parcel", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		s.put("parcels", o.ID, o)
+	case *easypost.Shipment:
+		stamp(&o.ID, "shp", &o.Object, "Shipment", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		for _, address := range []*easypost.Address{o.ToAddress, o.FromAddress, o.ReturnAddress, o.BuyerAddress} {
+			if address != nil {
+				_ = s.store(address)
+			}
+		}
+		if o.Parcel != nil {
+			_ = s.store(o.Parcel)
+		}
+		if o.Tracker != nil {
+			_ = s.store(o.Tracker)
+		}
+		if len(o.Rates) == 0 && o.PostageLabel == nil {
+			o.Rates = newRates(o)
+		}
+		if o.Status == "" {
+			o.Status = "unknown"
+		}
+		s.put("shipments", o.ID, o)
+	case *easypost.Tracker:
+		stamp(&o.ID, "trk", &o.Object, "Tracker", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		if o.Status == "" {
+			o.Status = trackerStatusOf(o.TrackingCode)
+		}
+		s.put("trackers", o.ID, o)
+	case *easypost.Batch:
+		stamp(&o.ID, "batch", &o.Object, "Batch", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		for _, shipment := range o.Shipments {
+			_ = s.store(shipment)
+		}
+		if o.State == "" {
+			o.State = "created"
+		}
+		o.NumShipments = len(o.Shipments)
+		s.put("batches", o.ID, o)
+	case *easypost.Webhook:
+		stamp(&o.ID, "hook", &o.Object, "Webhook", &o.Mode)
+		// like the API, the secret is never returned
+		s.webhookSecrets[o.ID] = o.WebhookSecret
+		o.WebhookSecret = ""
+		s.put("webhooks", o.ID, o)
+	case *easypost.Event:
+		stamp(&o.ID, "evt", &o.Object, "Event", &o.Mode)
+		timestamps(&o.CreatedAt, &o.UpdatedAt)
+		s.put("events", o.ID, o)
+	default:
+		return fmt.Errorf("easyposttest: cannot store objects of type %T", object)
+	}
+	return nil
+}
+
+// Seed adds objects to the state of the server, as if they had been created through the API: missing IDs and
+// creation times are generated, shipments without rates are given rates and the objects nested in shipments and
+// batches (e.g. their addresses) are added too. An object with the ID of an existing one replaces it. The supported
+// types are *easypost.Address, *easypost.Parcel, *easypost.Shipment, *easypost.Tracker, *easypost.Batch,
+// *easypost.Webhook and *easypost.Event. Seeding does not create events.
+func (s *Server) Seed(objects ...interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, object := range objects {
+		if err := s.store(object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the object of a collection with the given ID, or nil if there is none.
+func (s *Server) lookup(collection, id string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, _ := s.find(collection, id)
+	return object
+}
+
+// all returns the objects of a collection, oldest first.
+func (s *Server) all(collection string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := make([]interface{}, len(s.ids[collection]))
+	for i, id := range s.ids[collection] {
+		objects[i] = s.objects[id]
+	}
+	return objects
+}
+
+// Address returns a copy of the address with the given ID, or nil if there is none.
+func (s *Server) Address(id string) *easypost.Address {
+	if address, ok := s.lookup("addresses", id).(*easypost.Address); ok {
+		c := *address
+		return &c
+	}
+	return nil
+}
+
+// Parcel returns a copy of the parcel with the given ID, or nil if there is none.
+func (s *Server) Parcel(id string) *easypost.Parcel {
+	if parcel, ok := s.lookup("parcels", id).(*easypost.Parcel); ok {
+		c := *parcel
+		return &c
+	}
+	return nil
+}
+
+// Shipment returns a copy of the shipment with the given ID, or nil if there is none.
+func (s *Server) Shipment(id string) *easypost.Shipment {
+	if shipment, ok := s.lookup("shipments", id).(*easypost.Shipment); ok {
+		c := *shipment
+		return &c
+	}
+	return nil
+}
+
+// Shipments returns copies of every shipment, oldest first.
+func (s *Server) Shipments() []*easypost.Shipment {
+	var shipments []*easypost.Shipment
+	for _, object := range s.all("shipments") {
+		c := *object.(*easypost.Shipment)
+		shipments = append(shipments, &c)
+	}
+	return shipments
+}
+
+// Tracker returns a copy of the tracker with the given ID, or nil if there is none.
+func (s *Server) Tracker(id string) *easypost.Tracker {
+	if tracker, ok := s.lookup("trackers", id).(*easypost.Tracker); ok {
+		c := *tracker
+		return &c
+	}
+	return nil
+}
+
+// Trackers returns copies of every tracker, oldest first.
+func (s *Server) Trackers() []*easypost.Tracker {
+	var trackers []*easypost.Tracker
+	for _, object := range s.all("trackers") {
+		c := *object.(*easypost.Tracker)
+		trackers = append(trackers, &c)
+	}
+	return trackers
+}
+
+// Batch returns a copy of the batch with the given ID, or nil if there is none.
+func (s *Server) Batch(id string) *easypost.Batch {
+	if batch, ok := s.lookup("batches", id).(*easypost.Batch); ok {
+		c := *batch
+		return &c
+	}
+	return nil
+}
+
+// Webhook returns a copy of the webhook with the given ID, or nil if there is none.
+func (s *Server) Webhook(id string) *easypost.Webhook {
+	if webhook, ok := s.lookup("webhooks", id).(*easypost.Webhook); ok {
+		c := *webhook
+		return &c
+	}
+	return nil
+}
+
+// Event returns a copy of the event with the given ID, or nil if there is none.
+func (s *Server) Event(id string) *easypost.Event {
+	if event, ok := s.lookup("events", id).(*easypost.Event); ok {
+		c := *event
+		return &c
+	}
+	return nil
+}
+
+// Events returns copies of every event, oldest first.
+func (s *Server) Events() []*easypost.Event {
+	var events []*easypost.Event
+	for _, object := range s.all("events") {
+		c := *object.(*easypost.Event)
+		events = append(events, &c)
+	}
+	return events
+}
+
+// EventPayloads returns the deliveries of the event with the given ID to the webhooks, in order.
+func (s *Server) EventPayloads(eventID string) []*easypost.EventPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*easypost.EventPayload(nil), s.payloads[eventID]...)
+}
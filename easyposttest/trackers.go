@@ -0,0 +1,151 @@
+package easyposttest
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/EasyPost/easypost-go/v5"
+)
+
+// trackerProgression is the sequence of statuses AdvanceTracker moves a tracker through.
+var trackerProgression = []string{"pre_transit", "in_transit", "out_for_delivery", "delivered"}
+
+// testTrackingCodeStatuses are the statuses of the test tracking codes of the API, e.g. "EZ4000000004" is delivered.
+var testTrackingCodeStatuses = map[byte]string{
+	'1': "pre_transit",
+	'2': "in_transit",
+	'3': "out_for_delivery",
+	'4': "delivered",
+	'5': "return_to_sender",
+	'6': "failure",
+	'7': "unknown",
+}
+
+var trackingMessages = map[string]string{
+	"pre_transit":      "Pre-Shipment information sent to the carrier",
+	"in_transit":       "Arrived at the carrier facility",
+	"out_for_delivery": "Out for delivery",
+	"delivered":        "Delivered",
+	"return_to_sender": "Returned to sender",
+	"failure":          "Delivery failure",
+	"unknown":          "Unknown tracking status",
+}
+
+// trackerStatusOf returns the status a tracker starts in: the one of a test tracking code, or else pre_transit.
+func trackerStatusOf(trackingCode string) string {
+	if len(trackingCode) > 2 && trackingCode[:2] == "EZ" {
+		if status, ok := testTrackingCodeStatuses[trackingCode[2]]; ok {
+			return status
+		}
+	}
+	return trackerProgression[0]
+}
+
+// addTrackingDetail records that a tracker moved to a status.
+func addTrackingDetail(tracker *easypost.Tracker, status string) {
+	tracker.Status = status
+	tracker.UpdatedAt = now()
+	tracker.TrackingDetails = append(tracker.TrackingDetails, &easypost.TrackingDetail{
+		Object:   "TrackingDetail",
+		Message:  trackingMessages[status],
+		Status:   status,
+		DateTime: time.Time(*tracker.UpdatedAt).Format(time.RFC3339),
+		Source:   tracker.Carrier,
+	})
+}
+
+// newTracker creates and stores a tracker, with the tracking details of the statuses it went through.
+func (s *Server) newTracker(trackingCode, carrier, shipmentID string) *easypost.Tracker {
+	status := trackerStatusOf(trackingCode)
+	estDeliveryDate := easypost.DateTimeFromTime(time.Time(*now()).AddDate(0, 0, 3))
+	tracker := &easypost.Tracker{
+		TrackingCode:    trackingCode,
+		Carrier:         carrier,
+		ShipmentID:      shipmentID,
+		EstDeliveryDate: &estDeliveryDate,
+	}
+	for _, progression := range trackerProgression {
+		if progression == status {
+			break
+		}
+		addTrackingDetail(tracker, progression)
+	}
+	addTrackingDetail(tracker, status)
+	tracker.CreatedAt = tracker.UpdatedAt
+
+	_ = s.store(tracker)
+	s.emit("tracker.created", tracker)
+	return tracker
+}
+
+func (s *Server) createTracker(req *request) (interface{}, error) {
+	var body struct {
+		Tracker struct {
+			TrackingCode string `json:"tracking_code"`
+			Carrier      string `json:"carrier"`
+		} `json:"tracker"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	trackingCode, carrier := body.Tracker.TrackingCode, body.Tracker.Carrier
+	if trackingCode == "" {
+		return nil, newInvalidRequestError("TRACKER.TRACKING_CODE.INVALID", "Missing required parameter: tracking_code")
+	}
+	if carrier == "" {
+		carrier = "USPS"
+	}
+
+	// like the API, a tracker already created for the same tracking code is returned
+	for _, id := range s.ids["trackers"] {
+		if tracker := s.objects[id].(*easypost.Tracker); tracker.TrackingCode == trackingCode && tracker.Carrier == carrier {
+			return tracker, nil
+		}
+	}
+	return s.newTracker(trackingCode, carrier, ""), nil
+}
+
+// filterTracker honors the tracking_code and carrier query parameters of the tracker list.
+func filterTracker(object interface{}, query url.Values) bool {
+	tracker := object.(*easypost.Tracker)
+	if trackingCode := query.Get("tracking_code"); trackingCode != "" && tracker.TrackingCode != trackingCode {
+		return false
+	}
+	if carrier := query.Get("carrier"); carrier != "" && tracker.Carrier != carrier {
+		return false
+	}
+	return true
+}
+
+// AdvanceTracker simulates the progress of a parcel, moving the tracker with the given ID to the next status of
+// pre_transit, in_transit, out_for_delivery and delivered. A tracker.updated event is created and delivered to the
+// webhooks, and the shipment of the tracker, if any, takes the same status. Trackers that are delivered, or in a
+// status outside of the progression, are left as is. It returns a copy of the tracker.
+func (s *Server) AdvanceTracker(id string) (*easypost.Tracker, error) {
+	s.mu.Lock()
+	object, err := s.find("trackers", id)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("easyposttest: no tracker with ID %s", id)
+	}
+	tracker := object.(*easypost.Tracker)
+	for i, status := range trackerProgression[:len(trackerProgression)-1] {
+		if tracker.Status != status {
+			continue
+		}
+		addTrackingDetail(tracker, trackerProgression[i+1])
+		if shipment, ok := s.objects[tracker.ShipmentID].(*easypost.Shipment); ok {
+			shipment.Status = tracker.Status
+			shipment.UpdatedAt = tracker.UpdatedAt
+		}
+		s.emit("tracker.updated", tracker)
+		break
+	}
+	c := *tracker
+	events := s.takePendingEvents()
+	s.mu.Unlock()
+
+	s.deliver(events)
+	return &c, nil
+}
@@ -0,0 +1,211 @@
+package easyposttest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/EasyPost/easypost-go/v5"
+	"golang.org/x/text/unicode/norm"
+)
+
+// webhookTarget is a webhook an event is delivered to.
+type webhookTarget struct {
+	url           string
+	secret        string
+	customHeaders []easypost.WebhookCustomHeader
+}
+
+// pendingEvent is an event waiting to be delivered to the webhooks registered when it was created.
+type pendingEvent struct {
+	event   *easypost.Event
+	body    []byte
+	targets []webhookTarget
+}
+
+func (s *Server) createWebhook(req *request) (interface{}, error) {
+	var body struct {
+		Webhook *easypost.Webhook `json:"webhook"`
+	}
+	if err := req.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Webhook == nil || body.Webhook.URL == "" {
+		return nil, newInvalidRequestError("WEBHOOK.URL.INVALID", "Missing required parameter: url")
+	}
+	if u, err := url.Parse(body.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, newInvalidRequestError("WEBHOOK.URL.INVALID", "The webhook URL must be an HTTP or HTTPS URL.")
+	}
+
+	webhook := &easypost.Webhook{
+		URL:           body.Webhook.URL,
+		WebhookSecret: body.Webhook.WebhookSecret,
+		CustomHeaders: body.Webhook.CustomHeaders,
+	}
+	return webhook, s.store(webhook)
+}
+
+func (s *Server) listWebhooks(*request) (interface{}, error) {
+	webhooks := []interface{}{}
+	for _, id := range s.ids["webhooks"] {
+		webhooks = append(webhooks, s.objects[id])
+	}
+	return map[string]interface{}{"webhooks": webhooks}, nil
+}
+
+func (s *Server) updateWebhook(req *request) (interface{}, error) {
+	object, err := s.find("webhooks", req.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		WebhookSecret string                         `json:"webhook_secret"`
+		CustomHeaders []easypost.WebhookCustomHeader `json:"custom_headers"`
+	}
+	if err = req.decode(&body); err != nil {
+		return nil, err
+	}
+
+	// like the API, updating a webhook enables it again
+	webhook := object.(*easypost.Webhook)
+	webhook.DisabledAt = nil
+	if body.WebhookSecret != "" {
+		s.webhookSecrets[webhook.ID] = body.WebhookSecret
+	}
+	if body.CustomHeaders != nil {
+		webhook.CustomHeaders = body.CustomHeaders
+	}
+	return webhook, nil
+}
+
+func (s *Server) listEventPayloads(req *request) (interface{}, error) {
+	if _, err := s.find("events", req.ids[0]); err != nil {
+		return nil, err
+	}
+	payloads := append([]*easypost.EventPayload{}, s.payloads[req.ids[0]]...)
+	return map[string]interface{}{"payloads": payloads}, nil
+}
+
+func (s *Server) getEventPayload(req *request) (interface{}, error) {
+	for _, payload := range s.payloads[req.ids[0]] {
+		if payload.ID == req.ids[1] {
+			return payload, nil
+		}
+	}
+	return nil, newNotFoundError()
+}
+
+// emit creates an event with a snapshot of the object it describes, to be delivered to the enabled webhooks once the
+// state is unlocked.
+func (s *Server) emit(description string, object interface{}) {
+	event := &easypost.Event{Description: description, Status: "completed"}
+	if encoded, err := json.Marshal(object); err == nil {
+		event.Result, _ = easypost.UnmarshalJSONObject(encoded)
+	}
+
+	var targets []webhookTarget
+	for _, id := range s.ids["webhooks"] {
+		webhook := s.objects[id].(*easypost.Webhook)
+		if webhook.DisabledAt != nil {
+			continue
+		}
+		targets = append(targets, webhookTarget{url: webhook.URL, secret: s.webhookSecrets[id], customHeaders: webhook.CustomHeaders})
+		event.PendingURLs = append(event.PendingURLs, webhook.URL)
+	}
+	if len(targets) > 0 {
+		event.Status = "pending"
+	}
+	_ = s.store(event)
+
+	body, _ := json.Marshal(event)
+	s.pendingEvents = append(s.pendingEvents, &pendingEvent{event: event, body: body, targets: targets})
+}
+
+// takePendingEvents returns the events waiting to be delivered and forgets them.
+func (s *Server) takePendingEvents() []*pendingEvent {
+	events := s.pendingEvents
+	s.pendingEvents = nil
+	return events
+}
+
+// deliver sends events to their webhooks, signed with the secret of each webhook like the API does, and records the
+// deliveries as the payloads of the events. It must be called with the state unlocked, as a webhook may call the
+// server.
+func (s *Server) deliver(events []*pendingEvent) {
+	for _, pending := range events {
+		var payloads []*easypost.EventPayload
+		for _, target := range pending.targets {
+			payloads = append(payloads, s.send(target, pending.body))
+		}
+
+		s.mu.Lock()
+		s.payloads[pending.event.ID] = append(s.payloads[pending.event.ID], payloads...)
+		if len(payloads) > 0 {
+			pending.event.Status = "completed"
+			pending.event.PendingURLs = nil
+			for _, payload := range payloads {
+				if payload.ResponseCode < 200 || payload.ResponseCode > 299 {
+					pending.event.Status = "failed"
+					continue
+				}
+				pending.event.CompletedURLs = append(pending.event.CompletedURLs, payload.RequestURL)
+			}
+			pending.event.UpdatedAt = now()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// send posts the body of an event to a webhook and returns the resulting payload.
+func (s *Server) send(target webhookTarget, body []byte) *easypost.EventPayload {
+	createdAt := now()
+	payload := &easypost.EventPayload{
+		ID:             newID("payload"),
+		Object:         "Payload",
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+		RequestURL:     target.url,
+		RequestHeaders: map[string]string{"Content-Type": "application/json", "User-Agent": "easyposttest"},
+		RequestBody:    string(body),
+	}
+	if target.secret != "" {
+		signature := hmac.New(sha256.New, []byte(norm.NFKD.String(target.secret)))
+		signature.Write(body)
+		payload.RequestHeaders["X-Hmac-Signature"] = "hmac-sha256-hex=" + hex.EncodeToString(signature.Sum(nil))
+	}
+	for _, header := range target.customHeaders {
+		payload.RequestHeaders[header.Name] = header.Value
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.url, bytes.NewReader(body))
+	if err != nil {
+		payload.ResponseBody = err.Error()
+		return payload
+	}
+	for name, value := range payload.RequestHeaders {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	res, err := s.webhookClient.Do(req)
+	payload.TotalTime = int(time.Since(start).Milliseconds())
+	if err != nil {
+		payload.ResponseBody = err.Error()
+		return payload
+	}
+	defer func() { _ = res.Body.Close() }()
+	responseBody, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	payload.ResponseCode = res.StatusCode
+	payload.ResponseBody = string(responseBody)
+	payload.ResponseHeaders = map[string]string{}
+	for name := range res.Header {
+		payload.ResponseHeaders[name] = res.Header.Get(name)
+	}
+	return payload
+}
@@ -1,10 +1,16 @@
 package easypost
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -12,11 +18,33 @@ import (
 type LibraryError struct {
 	// Message is a human-readable error description.
 	Message interface{}
+
+	cause error
 }
 
 // Error provides a pretty printed string of a LibraryError object.
 func (e *LibraryError) Error() string {
-	return e.Message.(string)
+	return formatMessage(e.Message)
+}
+
+// formatMessage returns the string representation of an error message, which the API may return as a string, a list
+// or a map of messages.
+func formatMessage(message interface{}) string {
+	switch message := message.(type) {
+	case string:
+		return message
+	case nil:
+		return ""
+	case []interface{}, map[string]interface{}:
+		return strings.Join(collectMessages(message, []string{}), ", ")
+	default:
+		return fmt.Sprint(message)
+	}
+}
+
+// Unwrap returns the error that caused this error, if any.
+func (e *LibraryError) Unwrap() error {
+	return e.cause
 }
 
 func (e *LibraryError) UnmarshalJSON(data []byte) error {
@@ -189,6 +217,76 @@ func newInvalidFunctionError(message string) *InvalidFunctionError {
 	return &InvalidFunctionError{LocalError{LibraryError{Message: message}}}
 }
 
+// CircuitBreakerOpenError is raised when an API call is not attempted because the client's CircuitBreaker is open.
+type CircuitBreakerOpenError struct {
+	LocalError // subtype of LocalError
+}
+
+// Unwrap returns the underlying LocalError error.
+func (e *CircuitBreakerOpenError) Unwrap() error {
+	return &e.LocalError
+}
+
+// newCircuitBreakerOpenError returns a new CircuitBreakerOpenError object.
+func newCircuitBreakerOpenError() *CircuitBreakerOpenError {
+	return &CircuitBreakerOpenError{LocalError{LibraryError{Message: CircuitBreakerIsOpen}}}
+}
+
+// ProductionModeError is raised when a purchase API call is refused because the client's SafeMode is enabled in
+// production mode.
+type ProductionModeError struct {
+	LocalError // subtype of LocalError
+}
+
+// Unwrap returns the underlying LocalError error.
+func (e *ProductionModeError) Unwrap() error {
+	return &e.LocalError
+}
+
+// newProductionModeError returns a new ProductionModeError object for the given API call (e.g. "POST shipments/{id}/buy").
+func newProductionModeError(call string) *ProductionModeError {
+	return &ProductionModeError{LocalError{LibraryError{Message: PurchaseRefusedInProductionMode + call}}}
+}
+
+// IdempotencyKeyReuseError is raised when a context carrying an idempotency key is passed to a different API call
+// (another method or path) than the one the key was first sent with. The API call was not sent.
+type IdempotencyKeyReuseError struct {
+	LocalError // subtype of LocalError
+}
+
+// Unwrap returns the underlying LocalError error.
+func (e *IdempotencyKeyReuseError) Unwrap() error {
+	return &e.LocalError
+}
+
+// newIdempotencyKeyReuseError returns a new IdempotencyKeyReuseError object for the API call the key was first used
+// with (e.g. "POST shipments").
+func newIdempotencyKeyReuseError(call string) *IdempotencyKeyReuseError {
+	return &IdempotencyKeyReuseError{LocalError{LibraryError{Message: IdempotencyKeyReused + call}}}
+}
+
+// RequestHookError is raised when a RequestHookEventSubscriberCallback returns an error and the client's Hooks are
+// set to AbortOnRequestHookError. The API call was not sent. The callback's error is available through errors.Is and
+// errors.As.
+type RequestHookError struct {
+	LocalError // subtype of LocalError
+	// SubscriberID is the ID of the RequestHookEventSubscriber that aborted the API call.
+	SubscriberID string
+}
+
+// Unwrap returns the underlying LocalError error.
+func (e *RequestHookError) Unwrap() error {
+	return &e.LocalError
+}
+
+// newRequestHookError returns a new RequestHookError object.
+func newRequestHookError(subscriberID string, err error) *RequestHookError {
+	return &RequestHookError{
+		LocalError:   LocalError{LibraryError{Message: RequestHookAbortedCall + err.Error(), cause: err}},
+		SubscriberID: subscriberID,
+	}
+}
+
 // API/HTTP error types
 
 // APIError represents an error that occurred while communicating with the EasyPost API.
@@ -203,21 +301,19 @@ type APIError struct {
 	// StatusCode is the HTTP numerical status code of the response.
 	StatusCode int
 	// Errors may be provided if there are details about server-side issues that caused the API request to fail.
+	// Errors built from an API response always hold a []*FieldError, whatever the format returned by the API.
+	// FieldErrors returns them as a list of FieldErrors.
 	Errors interface{} `json:"errors,omitempty" url:"errors,omitempty"`
+	// IdempotencyKey is the Idempotency-Key header sent with the failed request, if any.
+	IdempotencyKey string
+	// Metadata describes the response of the failed request, including the request ID to quote to EasyPost support.
+	// It is nil if the error was not returned by an API call (e.g. when built with BuildErrorFromResponse).
+	Metadata *ResponseMetadata
 }
 
 // Error provides a pretty printed string of an APIError object based on present data.
 func (e *APIError) Error() string {
-	var message string
-	switch msg := e.Message.(type) {
-	case string:
-		message = msg
-	case []interface{}, map[string]interface{}:
-		messages := collectMessages(msg, []string{})
-		message = strings.Join(messages, ", ")
-	default:
-		message = fmt.Sprint(msg)
-	}
+	message := formatMessage(e.Message)
 
 	if message != "" {
 		if e.Code != "" {
@@ -257,7 +353,8 @@ func (e *BadRequestError) Unwrap() error {
 	return &e.APIError
 }
 
-// ConnectionError is raised when the API returns a 0 status code.
+// ConnectionError is raised when the API cannot be reached (e.g. DNS failure, connection refused or reset), or when
+// the API returns a 0 status code. For network failures, the original error is available through errors.As.
 type ConnectionError struct {
 	APIError // subtype of APIError
 }
@@ -327,7 +424,8 @@ func (e *PaymentError) Unwrap() error {
 	return &e.APIError
 }
 
-// ProxyError is raised when the API returns a 407 status code.
+// ProxyError is raised when the connection to the API through a proxy fails, or when the API returns a 407 status
+// code. For network failures, the original error is available through errors.As.
 type ProxyError struct {
 	APIError // subtype of APIError
 }
@@ -377,7 +475,8 @@ func (e *ServiceUnavailableError) Unwrap() error {
 	return &e.APIError
 }
 
-// SSLError is raised when there is an issue with the SSL certificate.
+// SSLError is raised when there is an issue with the SSL certificate or the TLS handshake with the API. The original
+// error is available through errors.As.
 type SSLError struct {
 	APIError // subtype of APIError
 }
@@ -387,7 +486,8 @@ func (e *SSLError) Unwrap() error {
 	return &e.APIError
 }
 
-// TimeoutError is raised when the API returns a 408 status code.
+// TimeoutError is raised when an API call times out, or when the API returns a 408 status code. For network failures,
+// the original error is available through errors.As.
 type TimeoutError struct {
 	APIError // subtype of APIError
 }
@@ -450,33 +550,9 @@ func BuildErrorFromResponse(response *http.Response) error {
 		if tmpError.Error != nil {
 			apiError.Code = tmpError.Error.Code
 			apiError.Message = tmpError.Error.Message
-			if tmpError.Error.Errors != nil {
-				switch errors := tmpError.Error.Errors.(type) {
-				case []interface{}:
-					var errorList []interface{}
-					for _, err := range errors {
-						switch err := err.(type) {
-						case map[string]interface{}:
-							fieldError := &FieldError{}
-							if field, ok := err["field"].(string); ok {
-								fieldError.Field = field
-							}
-							if message, ok := err["message"].(string); ok {
-								fieldError.Message = message
-							}
-							if suggestion, ok := err["suggestion"].(string); ok {
-								fieldError.Suggestion = suggestion
-							}
-							errorList = append(errorList, fieldError)
-						default:
-							errorList = append(errorList, fmt.Sprint(err))
-						}
-					}
-					apiError.Errors = errorList
-				default:
-					apiError.Errors = errors
-				}
-			}
+			// normalize the errors, which the API may return as a list or as a map keyed by field, to a list of FieldErrors
+			apiError.Errors = tmpError.Error.Errors
+			apiError.Errors = apiError.FieldErrors()
 		}
 	} else {
 		// could not extract error details from the API response (or API did not return data, i.e. 1xx, 3xx or 5xx)
@@ -526,3 +602,122 @@ func BuildErrorFromResponse(response *http.Response) error {
 		return &UnknownHttpError{APIError: *apiError}
 	}
 }
+
+// parseFieldError converts an item of the "errors" of an error response into a FieldError.
+func parseFieldError(item map[string]interface{}) *FieldError {
+	fieldError := &FieldError{}
+	if field, ok := item["field"].(string); ok {
+		fieldError.Field = field
+	}
+	if message, ok := item["message"].(string); ok {
+		fieldError.Message = message
+	}
+	if suggestion, ok := item["suggestion"].(string); ok {
+		fieldError.Suggestion = suggestion
+	}
+	return fieldError
+}
+
+// FieldErrors returns the Errors of the error as a list of FieldErrors, never nil. The API usually returns a list of
+// field errors, but may also return a list of messages or a map of messages keyed by field, which are converted to
+// FieldErrors.
+func (e *APIError) FieldErrors() []*FieldError {
+	fieldErrors := []*FieldError{}
+
+	switch data := e.Errors.(type) {
+	case nil:
+	case []*FieldError:
+		fieldErrors = append(fieldErrors, data...)
+	case []interface{}:
+		for _, item := range data {
+			switch item := item.(type) {
+			case *FieldError:
+				fieldErrors = append(fieldErrors, item)
+			case map[string]interface{}:
+				fieldErrors = append(fieldErrors, parseFieldError(item))
+			default:
+				fieldErrors = append(fieldErrors, &FieldError{Message: fmt.Sprint(item)})
+			}
+		}
+	case map[string]interface{}:
+		fields := make([]string, 0, len(data))
+		for field := range data {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fieldErrors = append(fieldErrors, &FieldError{Field: field, Message: formatMessage(data[field])})
+		}
+	default:
+		fieldErrors = append(fieldErrors, &FieldError{Message: fmt.Sprint(data)})
+	}
+
+	return fieldErrors
+}
+
+// newTransportError classifies an error returned while sending a request to the API or reading its response into a
+// ConnectionError, TimeoutError, SSLError or ProxyError, keeping the original error reachable through Unwrap.
+//
+// Errors that are already part of the library's error hierarchy, and cancellations of the caller's context, are
+// returned as-is.
+func newTransportError(err error) error {
+	var libraryError *LibraryError
+	if err == nil || errors.As(err, &libraryError) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	apiError := APIError{LibraryError: LibraryError{Message: err.Error(), cause: err}}
+
+	var netError net.Error
+	var opError *net.OpError
+	var recordHeaderError tls.RecordHeaderError
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netError) && netError.Timeout():
+		return &TimeoutError{APIError: apiError}
+	case errors.As(err, &opError) && opError.Op == "proxyconnect":
+		return &ProxyError{APIError: apiError}
+	case errors.As(err, &recordHeaderError), errors.As(err, &unknownAuthorityError), errors.As(err, &hostnameError),
+		errors.As(err, &certificateInvalidError):
+		return &SSLError{APIError: apiError}
+	default:
+		return &ConnectionError{APIError: apiError}
+	}
+}
+
+// IsRetryable returns true if the error is transient, i.e. a RateLimitError, ServiceUnavailableError,
+// GatewayTimeoutError or a failure to reach the API (a ConnectionError or TimeoutError), so the API call can be
+// attempted again.
+func IsRetryable(err error) bool {
+	var rateLimitError *RateLimitError
+	var serviceUnavailableError *ServiceUnavailableError
+	var gatewayTimeoutError *GatewayTimeoutError
+	return errors.As(err, &rateLimitError) || errors.As(err, &serviceUnavailableError) ||
+		errors.As(err, &gatewayTimeoutError) || isTransportError(err)
+}
+
+// IsClientError returns true if the error is an APIError with a 4xx status code, i.e. the request needs to be fixed
+// before it is attempted again.
+func IsClientError(err error) bool {
+	var apiError *APIError
+	return errors.As(err, &apiError) && apiError.StatusCode >= 400 && apiError.StatusCode <= 499
+}
+
+// IsPaymentRequired returns true if the error is a PaymentError, i.e. the account's balance or payment method does not
+// allow the API call.
+func IsPaymentRequired(err error) bool {
+	var paymentError *PaymentError
+	return errors.As(err, &paymentError)
+}
+
+// ErrorCode returns the error code of the APIError wrapped by the error, or an empty code if the error is not an
+// APIError. Use Category on the returned code to know what kind of problem it reports.
+func ErrorCode(err error) APIErrorCode {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return APIErrorCode(apiError.Code)
+	}
+	return ""
+}
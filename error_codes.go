@@ -0,0 +1,88 @@
+package easypost
+
+// ErrorCategory groups API error codes by the kind of problem they report.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryUnknown is the category of error codes missing from the catalog.
+	ErrorCategoryUnknown ErrorCategory = "unknown"
+	// ErrorCategoryAddress reports an address that could not be verified or found.
+	ErrorCategoryAddress ErrorCategory = "address"
+	// ErrorCategoryAuthentication reports a missing or invalid API key.
+	ErrorCategoryAuthentication ErrorCategory = "authentication"
+	// ErrorCategoryBilling reports a problem with a payment method or a wallet transaction.
+	ErrorCategoryBilling ErrorCategory = "billing"
+	// ErrorCategoryNotFound reports a resource that does not exist.
+	ErrorCategoryNotFound ErrorCategory = "not_found"
+	// ErrorCategoryPostage reports a label that could not be purchased.
+	ErrorCategoryPostage ErrorCategory = "postage"
+	// ErrorCategoryRateLimit reports that too many requests were sent.
+	ErrorCategoryRateLimit ErrorCategory = "rate_limit"
+	// ErrorCategoryServer reports a problem on the API's side.
+	ErrorCategoryServer ErrorCategory = "server"
+	// ErrorCategoryValidation reports missing or invalid request parameters.
+	ErrorCategoryValidation ErrorCategory = "validation"
+)
+
+// APIErrorCode is a machine-readable error code returned by the API in APIError.Code.
+type APIErrorCode string
+
+// Known API error codes.
+const (
+	ErrorCodeAddressNotFound                       APIErrorCode = "E.ADDRESS.NOT_FOUND"
+	ErrorCodeAddressVerifyFailure                  APIErrorCode = "ADDRESS.VERIFY.FAILURE"
+	ErrorCodeAPIKeyInactive                        APIErrorCode = "APIKEY.INACTIVE"
+	ErrorCodeAPIKeyRequired                        APIErrorCode = "APIKEY.REQUIRED"
+	ErrorCodeBankAccountInvalidParams              APIErrorCode = "BANK_ACCOUNT.INVALID_PARAMS"
+	ErrorCodeBillingInvalidPaymentGatewayReference APIErrorCode = "BILLING.INVALID_PAYMENT_GATEWAY_REFERENCE"
+	ErrorCodeCreditCardNotFound                    APIErrorCode = "CREDIT_CARD.NOT_FOUND"
+	ErrorCodeInternalServerError                   APIErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrorCodeNotFound                              APIErrorCode = "NOT_FOUND"
+	ErrorCodeParameterInvalid                      APIErrorCode = "PARAMETER.INVALID"
+	ErrorCodeParameterRequired                     APIErrorCode = "PARAMETER.REQUIRED"
+	ErrorCodePayloadNotFound                       APIErrorCode = "PAYLOAD.NOT_FOUND"
+	ErrorCodeRateLimited                           APIErrorCode = "RATE_LIMITED"
+	ErrorCodeShipmentInvalidParams                 APIErrorCode = "SHIPMENT.INVALID_PARAMS"
+	ErrorCodeShipmentPostageFailure                APIErrorCode = "SHIPMENT.POSTAGE.FAILURE"
+	ErrorCodeTransactionAmountInvalid              APIErrorCode = "TRANSACTION.AMOUNT_INVALID"
+	ErrorCodeTransactionDoesNotExist               APIErrorCode = "TRANSACTION.DOES_NOT_EXIST"
+	ErrorCodeUnprocessableEntity                   APIErrorCode = "UNPROCESSABLE_ENTITY"
+	// ErrorCodeResponseParseError is set by the library when the details of an error response could not be parsed.
+	ErrorCodeResponseParseError APIErrorCode = "RESPONSE.PARSE_ERROR"
+)
+
+var errorCodeCategories = map[APIErrorCode]ErrorCategory{
+	ErrorCodeAddressNotFound:                       ErrorCategoryAddress,
+	ErrorCodeAddressVerifyFailure:                  ErrorCategoryAddress,
+	ErrorCodeAPIKeyInactive:                        ErrorCategoryAuthentication,
+	ErrorCodeAPIKeyRequired:                        ErrorCategoryAuthentication,
+	ErrorCodeBankAccountInvalidParams:              ErrorCategoryBilling,
+	ErrorCodeBillingInvalidPaymentGatewayReference: ErrorCategoryBilling,
+	ErrorCodeCreditCardNotFound:                    ErrorCategoryBilling,
+	ErrorCodeInternalServerError:                   ErrorCategoryServer,
+	ErrorCodeNotFound:                              ErrorCategoryNotFound,
+	ErrorCodeParameterInvalid:                      ErrorCategoryValidation,
+	ErrorCodeParameterRequired:                     ErrorCategoryValidation,
+	ErrorCodePayloadNotFound:                       ErrorCategoryNotFound,
+	ErrorCodeRateLimited:                           ErrorCategoryRateLimit,
+	ErrorCodeShipmentInvalidParams:                 ErrorCategoryValidation,
+	ErrorCodeShipmentPostageFailure:                ErrorCategoryPostage,
+	ErrorCodeTransactionAmountInvalid:              ErrorCategoryBilling,
+	ErrorCodeTransactionDoesNotExist:               ErrorCategoryBilling,
+	ErrorCodeUnprocessableEntity:                   ErrorCategoryValidation,
+	ErrorCodeResponseParseError:                    ErrorCategoryServer,
+}
+
+// Category returns the category of the error code, or ErrorCategoryUnknown if the code is not in the catalog.
+func (c APIErrorCode) Category() ErrorCategory {
+	if category, ok := errorCodeCategories[c]; ok {
+		return category
+	}
+	return ErrorCategoryUnknown
+}
+
+// Known returns true if the error code is in the catalog.
+func (c APIErrorCode) Known() bool {
+	_, ok := errorCodeCategories[c]
+	return ok
+}
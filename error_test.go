@@ -1,9 +1,16 @@
 package easypost
 
 import (
+	"context"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 )
 
@@ -72,12 +79,10 @@ func (c *ClientTests) TestApiError() {
 		assert.Equal(422, invalidRequestError.StatusCode)
 		assert.Equal("PARAMETER.REQUIRED", invalidRequestError.Code)
 		assert.Equal("Missing required parameter.", invalidRequestError.Message)
-		if errorsList, ok := invalidRequestError.Errors.([]interface{}); ok {
+		if errorsList, ok := invalidRequestError.Errors.([]*FieldError); ok {
 			assert.Equal(1, len(errorsList))
-			if fieldError, ok := errorsList[0].(*FieldError); ok {
-				assert.Equal("shipment", fieldError.Field)
-				assert.Equal("cannot be blank", fieldError.Message)
-			}
+			assert.Equal("shipment", errorsList[0].Field)
+			assert.Equal("cannot be blank", errorsList[0].Message)
 		}
 	}
 
@@ -103,8 +108,8 @@ func (c *ClientTests) TestApiErrorAlternativeFormat() {
 		assert.Equal(404, notFoundError.StatusCode)
 		assert.Equal("NOT_FOUND", notFoundError.Code)
 		assert.Equal("The requested resource could not be found.", notFoundError.Message)
-		if errorsList, ok := notFoundError.Errors.([]interface{}); ok {
-			assert.Equal("No eligible insurance found with provided tracking code.", errorsList[0])
+		if errorsList, ok := notFoundError.Errors.([]*FieldError); ok {
+			assert.Equal("No eligible insurance found with provided tracking code.", errorsList[0].Message)
 		}
 	}
 
@@ -354,3 +359,165 @@ func (c *ClientTests) TestErrorMessageParseExtreme() {
 		assert.Contains(errorMessage, message)
 	}
 }
+
+func (c *ClientTests) TestTransportErrorConnectionRefused() {
+	assert, require := c.Assert(), c.Require()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL, err := url.Parse(server.URL + "/v2/")
+	require.NoError(err)
+	server.Close()
+
+	client := &Client{APIKey: "cannot_be_blank", BaseURL: serverURL}
+	_, err = client.GetAddress("adr_123")
+	require.Error(err)
+
+	var connectionError *ConnectionError
+	require.True(errors.As(err, &connectionError))
+	assert.Equal(0, connectionError.StatusCode)
+
+	// the original network error is still reachable
+	var opError *net.OpError
+	assert.True(errors.As(err, &opError))
+	var urlError *url.Error
+	assert.True(errors.As(err, &urlError))
+	var apiError *APIError
+	assert.True(errors.As(err, &apiError))
+}
+
+func (c *ClientTests) TestTransportErrorTimeout() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	client.Timeout = 10
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	var timeoutError *TimeoutError
+	assert.True(errors.As(err, &timeoutError))
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func (c *ClientTests) TestTransportErrorClassification() {
+	assert, require := c.Assert(), c.Require()
+
+	proxyErr := &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}
+	sslErr := x509.UnknownAuthorityError{}
+	dnsErr := &net.DNSError{Err: "no such host", Name: "api.easypost.com", IsNotFound: true}
+
+	for _, test := range []struct {
+		err      error
+		expected interface{}
+	}{
+		{proxyErr, &ProxyError{}},
+		{sslErr, &SSLError{}},
+		{dnsErr, &ConnectionError{}},
+		{io.ErrUnexpectedEOF, &ConnectionError{}},
+	} {
+		transportErr := test.err
+		client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+			return nil, transportErr
+		})
+
+		_, err := client.GetAddress("adr_123")
+		require.Error(err)
+
+		assert.Equal(reflect.TypeOf(test.expected), reflect.TypeOf(err), test.err.Error())
+		assert.True(errors.Is(err, transportErr))
+	}
+
+	// a cancelled call is not a transport failure
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	var apiError *APIError
+	assert.False(errors.As(err, &apiError))
+	assert.True(errors.Is(err, context.Canceled))
+}
+
+func (c *ClientTests) TestErrorClassificationHelpers() {
+	assert := c.Assert()
+
+	buildError := func(statusCode int, body string) error {
+		return BuildErrorFromResponse(&http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(body))})
+	}
+
+	postageFailure := buildError(422, `{"error": {"code": "SHIPMENT.POSTAGE.FAILURE", "message": "failed"}}`)
+	paymentRequired := buildError(402, `{"error": {"code": "TRANSACTION.AMOUNT_INVALID", "message": "insufficient funds"}}`)
+	rateLimited := buildError(429, `{"error": {"code": "RATE_LIMITED", "message": "slow down"}}`)
+	serverError := buildError(500, `{"error": {"code": "INTERNAL_SERVER_ERROR", "message": "oops"}}`)
+	connectionError := newTransportError(errors.New("connection reset by peer"))
+	localError := newMissingPropertyError("APIKey")
+
+	assert.False(IsRetryable(postageFailure))
+	assert.True(IsRetryable(rateLimited))
+	assert.True(IsRetryable(buildError(503, `{}`)))
+	assert.True(IsRetryable(connectionError))
+	assert.False(IsRetryable(serverError))
+	assert.False(IsRetryable(localError))
+	assert.False(IsRetryable(nil))
+
+	assert.True(IsClientError(postageFailure))
+	assert.True(IsClientError(paymentRequired))
+	assert.False(IsClientError(serverError))
+	assert.False(IsClientError(connectionError))
+	assert.False(IsClientError(localError))
+
+	assert.True(IsPaymentRequired(paymentRequired))
+	assert.False(IsPaymentRequired(postageFailure))
+
+	assert.Equal(ErrorCodeShipmentPostageFailure, ErrorCode(postageFailure))
+	assert.Equal(ErrorCategoryPostage, ErrorCode(postageFailure).Category())
+	assert.Equal(ErrorCategoryBilling, ErrorCode(fmt.Errorf("buying label: %w", paymentRequired)).Category())
+	assert.Equal(APIErrorCode(""), ErrorCode(localError))
+	assert.Equal(ErrorCategoryUnknown, APIErrorCode("SOMETHING.NEW").Category())
+	assert.False(APIErrorCode("SOMETHING.NEW").Known())
+	assert.True(ErrorCodeAddressVerifyFailure.Known())
+}
+
+func (c *ClientTests) TestApiErrorFieldErrorsNormalized() {
+	assert, require := c.Assert(), c.Require()
+
+	for body, expected := range map[string][]*FieldError{
+		`{"error": {"code": "NOT_FOUND", "message": "not found", "errors": ["No eligible insurance found"]}}`: {
+			{Message: "No eligible insurance found"},
+		},
+		`{"error": {"code": "PARAMETER.INVALID", "message": "invalid", "errors": {"weight": "must be positive", "length": ["too long"]}}}`: {
+			{Field: "length", Message: "too long"},
+			{Field: "weight", Message: "must be positive"},
+		},
+		`{"error": {"code": "PARAMETER.REQUIRED", "message": "missing", "errors": [{"field": "shipment", "message": "cannot be blank"}]}}`: {
+			{Field: "shipment", Message: "cannot be blank"},
+		},
+		`{"error": {"code": "NOT_FOUND", "message": "not found"}}`: {},
+		`not json`: {},
+	} {
+		err := BuildErrorFromResponse(&http.Response{StatusCode: 422, Body: io.NopCloser(strings.NewReader(body))})
+
+		var apiError *APIError
+		require.True(errors.As(err, &apiError))
+		assert.Equal(expected, apiError.FieldErrors(), body)
+	}
+
+	// Errors holds the normalized list rather than the format returned by the API
+	err := BuildErrorFromResponse(&http.Response{StatusCode: 422, Body: io.NopCloser(strings.NewReader(
+		`{"error": {"code": "PARAMETER.INVALID", "message": "invalid", "errors": {"weight": "must be positive"}}}`,
+	))})
+	var apiError *APIError
+	require.True(errors.As(err, &apiError))
+	assert.Equal([]*FieldError{{Field: "weight", Message: "must be positive"}}, apiError.Errors)
+}
+
+func (c *ClientTests) TestLibraryErrorNonStringMessage() {
+	assert := c.Assert()
+
+	assert.Equal("", (&LibraryError{}).Error())
+	assert.Equal("Bad format, Bad format 2", (&LibraryError{Message: []interface{}{"Bad format", "Bad format 2"}}).Error())
+	assert.Equal("42", (&LocalError{LibraryError{Message: 42}}).Error())
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
+	"reflect"
 )
 
 // Event objects contain details about changes to EasyPost objects
@@ -147,6 +149,110 @@ func (c *Client) GetNextEventPageWithPageSizeWithContext(ctx context.Context, co
 	return c.ListEventsWithContext(ctx, params)
 }
 
+// EventIterator iterates over a paginated collection of events. See IterateEvents.
+type EventIterator struct {
+	*pageIterator
+}
+
+// Event returns the current item of the iterator, or nil before the first call to Next and after the iteration stopped.
+func (it *EventIterator) Event() *Event {
+	event, _ := it.current.(*Event)
+	return event
+}
+
+// IterateEvents returns an iterator over the events matching opts, fetching the pages of the collection lazily as the
+// iteration goes.
+//
+//	it := c.IterateEvents(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Event().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateEvents(opts *ListOptions, iterOpts *IteratorOptions) *EventIterator {
+	return c.IterateEventsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateEventsWithContext performs the same operation as IterateEvents, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateEventsWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *EventIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListEventsWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Events))
+		for i, event := range page.Events {
+			items[i] = event
+		}
+		return items, page.HasMore, nil
+	}
+	return &EventIterator{newListIterator(ctx, "events", CursorBeforeID, &filters, iterOpts, list)}
+}
+
+// ListEventsInDateRange returns every event created between the StartDateTime and EndDateTime of opts, newest first.
+// The range is split into windows that are paged through concurrently as configured by rangeOpts, and events returned
+// by more than one window are only included once. The other filters of opts apply to every window.
+func (c *Client) ListEventsInDateRange(opts *ListOptions, rangeOpts *DateRangeOptions) (out []*Event, err error) {
+	return c.ListEventsInDateRangeWithContext(context.Background(), opts, rangeOpts)
+}
+
+// ListEventsInDateRangeWithContext performs the same operation as ListEventsInDateRange, but
+// allows specifying a context that can interrupt the requests.
+func (c *Client) ListEventsInDateRangeWithContext(ctx context.Context, opts *ListOptions, rangeOpts *DateRangeOptions) (out []*Event, err error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	fetch := func(ctx context.Context, start, end *DateTime) ([]interface{}, error) {
+		windowOpts := *opts
+		windowOpts.BeforeID, windowOpts.AfterID = "", ""
+		windowOpts.StartDateTime, windowOpts.EndDateTime = start, end
+
+		var items []interface{}
+		it := c.IterateEventsWithContext(ctx, &windowOpts, nil)
+		for it.Next() {
+			items = append(items, it.Event())
+		}
+		return items, it.Err()
+	}
+	idOf := func(item interface{}) string {
+		if event := item.(*Event); event != nil {
+			return event.ID
+		}
+		return ""
+	}
+
+	items, err := fetchDateRange(ctx, opts.StartDateTime, opts.EndDateTime, rangeOpts, fetch, idOf)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]*Event, len(items))
+	for i, item := range items {
+		out[i] = item.(*Event)
+	}
+	return out, nil
+}
+
+// ExportEvents streams every event matching opts to w in the format set by exportOpts, returning the number of
+// events written. The events are fetched one page at a time as they are written, so memory use does not grow
+// with the size of the collection; set the StartDateTime and EndDateTime of opts to export a date range.
+func (c *Client) ExportEvents(w io.Writer, opts *ListOptions, exportOpts *ExportOptions) (count int, err error) {
+	return c.ExportEventsWithContext(context.Background(), w, opts, exportOpts)
+}
+
+// ExportEventsWithContext performs the same operation as ExportEvents, but
+// allows specifying a context that can interrupt the export.
+func (c *Client) ExportEventsWithContext(ctx context.Context, w io.Writer, opts *ListOptions, exportOpts *ExportOptions) (count int, err error) {
+	it := c.IterateEventsWithContext(ctx, opts, nil)
+	return export(w, exportOpts, reflect.TypeOf(Event{}), defaultEventExportColumns, it.pageIterator)
+}
+
 // GetEvent retrieves a previously-created event by its ID.
 func (c *Client) GetEvent(eventID string) (out *Event, err error) {
 	return c.GetEventWithContext(context.Background(), eventID)
@@ -0,0 +1,274 @@
+package easypost
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the file format written by the Export* functions (e.g. ExportShipments).
+type ExportFormat string
+
+const (
+	// ExportJSONL writes one JSON object per line.
+	ExportJSONL ExportFormat = "jsonl"
+	// ExportCSV writes comma-separated values, preceded by a header row holding the column names.
+	ExportCSV ExportFormat = "csv"
+)
+
+var (
+	defaultShipmentExportColumns = []string{
+		"ID", "CreatedAt", "Mode", "Reference", "Status", "TrackingCode", "ToAddress.Zip", "SelectedRate.Carrier",
+		"SelectedRate.Service", "SelectedRate.Rate", "SelectedRate.Currency", "Tracker.Status",
+	}
+	defaultTrackerExportColumns = []string{
+		"ID", "CreatedAt", "Mode", "TrackingCode", "Carrier", "Status", "StatusDetail", "EstDeliveryDate", "ShipmentID",
+	}
+	defaultEventExportColumns = []string{
+		"ID", "CreatedAt", "Mode", "Description", "Status",
+	}
+)
+
+// ExportOptions configures how the Export* functions write the objects of a collection.
+type ExportOptions struct {
+	// Format is the file format written. If empty, ExportJSONL will be used.
+	Format ExportFormat
+	// Columns projects each object onto the given fields, in order. A column is a path of field names separated by
+	// dots that reaches into nested objects, such as "SelectedRate.Rate" or "ToAddress.Zip". Both the Go and the JSON
+	// name of a field are accepted, e.g. "TrackingCode" or "tracking_code". A column whose path reaches a nil object
+	// is empty. If empty, JSONL exports write whole objects and CSV exports write a default set of columns for the
+	// collection.
+	Columns []string
+	// OmitHeader skips the header row of CSV exports, for instance when appending to an existing file.
+	OmitHeader bool
+}
+
+// exportColumn is a column of an export, resolved against the type of the exported objects.
+type exportColumn struct {
+	name string
+	path []string
+}
+
+// newExportColumns resolves the given columns against typ, returning an error for any path that does not name a field.
+// The part of a path that follows a map or an interface field can only be resolved against each object.
+func newExportColumns(typ reflect.Type, names []string) ([]exportColumn, error) {
+	columns := make([]exportColumn, len(names))
+	for i, name := range names {
+		columns[i] = exportColumn{name: name, path: strings.Split(name, ".")}
+		fieldType := typ
+	path:
+		for _, segment := range columns[i].path {
+			for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice {
+				if fieldType.Kind() == reflect.Slice {
+					return nil, newInvalidObjectError(InvalidParameter + "the export column " + name + " reaches into a list")
+				}
+				fieldType = fieldType.Elem()
+			}
+			switch fieldType.Kind() {
+			case reflect.Struct:
+				field, ok := exportField(fieldType, segment)
+				if !ok {
+					return nil, newInvalidObjectError(InvalidParameter + "unknown export column " + name)
+				}
+				fieldType = field.Type
+			case reflect.Map, reflect.Interface:
+				break path
+			default:
+				return nil, newInvalidObjectError(InvalidParameter + "unknown export column " + name)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// exportField returns the field of typ with the given Go or JSON name.
+func exportField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Name == name || strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// value returns the value of the column for the given object, or nil if its path reaches a nil object or a missing
+// key.
+func (col exportColumn) value(object interface{}) interface{} {
+	value := reflect.ValueOf(object)
+	for _, segment := range col.path {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return nil
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := exportField(value.Type(), segment)
+			if !ok {
+				return nil
+			}
+			value = value.FieldByIndex(field.Index)
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil
+			}
+			value = value.MapIndex(reflect.ValueOf(segment).Convert(value.Type().Key()))
+			if !value.IsValid() {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if dateTime, ok := value.Interface().(DateTime); ok {
+		return time.Time(dateTime)
+	}
+	return value.Interface()
+}
+
+// formatExportValue formats a column value as a CSV field. Nested objects and lists are written as JSON.
+func formatExportValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.String:
+		return fmt.Sprint(value), nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// exportWriter writes the objects of an export one at a time, so that only the page being exported is held in memory.
+type exportWriter struct {
+	format  ExportFormat
+	columns []exportColumn
+	json    *json.Encoder
+	csv     *csv.Writer
+}
+
+func newExportWriter(w io.Writer, options *ExportOptions, typ reflect.Type, defaultColumns []string) (*exportWriter, error) {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+	format := options.Format
+	if format == "" {
+		format = ExportJSONL
+	}
+
+	names := options.Columns
+	if len(names) == 0 && format == ExportCSV {
+		names = defaultColumns
+	}
+	columns, err := newExportColumns(typ, names)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &exportWriter{format: format, columns: columns}
+	switch format {
+	case ExportJSONL:
+		writer.json = json.NewEncoder(w)
+	case ExportCSV:
+		writer.csv = csv.NewWriter(w)
+		if !options.OmitHeader {
+			if err = writer.csv.Write(names); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, newInvalidObjectError(InvalidParameter + "unknown export format " + string(format))
+	}
+	return writer, nil
+}
+
+// write writes an object as a line of the export.
+func (w *exportWriter) write(object interface{}) error {
+	if w.format == ExportCSV {
+		record := make([]string, len(w.columns))
+		for i, col := range w.columns {
+			field, err := formatExportValue(col.value(object))
+			if err != nil {
+				return err
+			}
+			record[i] = field
+		}
+		return w.csv.Write(record)
+	}
+
+	if len(w.columns) == 0 {
+		return w.json.Encode(object)
+	}
+	// the projected object is built by hand to keep the columns in order
+	var line strings.Builder
+	line.WriteByte('{')
+	for i, col := range w.columns {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		key, err := json.Marshal(col.name)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(col.value(object))
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteByte('}')
+	return w.json.Encode(json.RawMessage(line.String()))
+}
+
+// flush writes any buffered data to the underlying writer.
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// export writes every item of the iterator, returning the number of objects written.
+func export(w io.Writer, options *ExportOptions, typ reflect.Type, defaultColumns []string, it *pageIterator) (int, error) {
+	writer, err := newExportWriter(w, options, typ, defaultColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for it.Next() {
+		if err = writer.write(it.current); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err = writer.flush(); err != nil {
+		return count, err
+	}
+	return count, it.Err()
+}
@@ -0,0 +1,130 @@
+package easypost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func (c *ClientTests) TestExportShipmentsToCSV() {
+	assert, require := c.Assert(), c.Require()
+
+	objects := []map[string]interface{}{
+		{
+			"id":            "shp_3",
+			"created_at":    "2026-01-03T00:00:00Z",
+			"to_address":    map[string]interface{}{"zip": "94107"},
+			"selected_rate": map[string]interface{}{"rate": "7.25", "carrier": "USPS"},
+			"tracker":       map[string]interface{}{"status": "delivered"},
+			"usps_zone":     4,
+		},
+		{"id": "shp_2", "created_at": "2026-01-02T00:00:00Z", "reference": "a \"quoted\", reference"},
+		{"id": "shp_1", "created_at": "2026-01-01T00:00:00Z"},
+	}
+	client, calls := c.ScriptedClient(listServer("shipments", objects))
+
+	var out bytes.Buffer
+	count, err := client.ExportShipments(&out, &ListShipmentsOptions{PageSize: 2}, &ExportOptions{
+		Format:  ExportCSV,
+		Columns: []string{"ID", "CreatedAt", "Reference", "SelectedRate.Rate", "ToAddress.Zip", "Tracker.Status", "usps_zone"},
+	})
+	require.NoError(err)
+	assert.Equal(3, count)
+	assert.Equal(2, *calls)
+	assert.Equal(strings.Join([]string{
+		"ID,CreatedAt,Reference,SelectedRate.Rate,ToAddress.Zip,Tracker.Status,usps_zone",
+		"shp_3,2026-01-03T00:00:00Z,,7.25,94107,delivered,4",
+		`shp_2,2026-01-02T00:00:00Z,"a ""quoted"", reference",,,,0`,
+		"shp_1,2026-01-01T00:00:00Z,,,,,0",
+	}, "\n")+"\n", out.String())
+
+	// the default columns are used when none are given
+	out.Reset()
+	_, err = client.ExportShipments(&out, nil, &ExportOptions{Format: ExportCSV, OmitHeader: true})
+	require.NoError(err)
+	assert.True(strings.HasPrefix(out.String(), "shp_3,2026-01-03T00:00:00Z,"))
+	assert.Contains(out.String(), ",94107,USPS,,7.25,,delivered\n")
+}
+
+func (c *ClientTests) TestExportTrackersToJSONL() {
+	assert, require := c.Assert(), c.Require()
+
+	objects := []map[string]interface{}{
+		{"id": "trk_2", "created_at": "2026-01-02T00:00:00Z", "status": "in_transit", "carrier": "USPS"},
+		{"id": "trk_1", "created_at": "2026-01-01T00:00:00Z", "status": "delivered", "carrier": "UPS"},
+	}
+	client, _ := c.ScriptedClient(listServer("trackers", objects))
+
+	var out bytes.Buffer
+	count, err := client.ExportTrackers(&out, nil, &ExportOptions{Columns: []string{"ID", "Status", "CreatedAt", "CarrierDetail.Service"}})
+	require.NoError(err)
+	assert.Equal(2, count)
+	assert.Equal(`{"ID":"trk_2","Status":"in_transit","CreatedAt":"2026-01-02T00:00:00Z","CarrierDetail.Service":null}`+"\n"+
+		`{"ID":"trk_1","Status":"delivered","CreatedAt":"2026-01-01T00:00:00Z","CarrierDetail.Service":null}`+"\n", out.String())
+
+	// whole objects are written when no columns are given
+	out.Reset()
+	_, err = client.ExportTrackers(&out, nil, nil)
+	require.NoError(err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(lines, 2)
+	var tracker Tracker
+	require.NoError(json.Unmarshal([]byte(lines[1]), &tracker))
+	assert.Equal("trk_1", tracker.ID)
+	assert.Equal("UPS", tracker.Carrier)
+}
+
+func (c *ClientTests) TestExportFilteredTrackersOverSeveralPages() {
+	assert, require := c.Assert(), c.Require()
+
+	objects := []map[string]interface{}{
+		{"id": "trk_4", "created_at": "2026-01-04T00:00:00Z", "tracking_code": "C"},
+		{"id": "trk_3", "created_at": "2026-01-03T00:00:00Z", "tracking_code": "B"},
+		{"id": "trk_2", "created_at": "2026-01-02T00:00:00Z", "tracking_code": "D"},
+		{"id": "trk_1", "created_at": "2026-01-01T00:00:00Z", "tracking_code": "A"},
+	}
+	// the server honors the tracking_codes filter, as the API does
+	client, calls := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		codes := map[string]bool{}
+		for _, code := range req.URL.Query()["tracking_codes"] {
+			codes[code] = true
+		}
+		var matching []map[string]interface{}
+		for _, object := range objects {
+			if len(codes) == 0 || codes[object["tracking_code"].(string)] {
+				matching = append(matching, object)
+			}
+		}
+		return listServer("trackers", matching)(req)
+	})
+
+	var out bytes.Buffer
+	count, err := client.ExportTrackers(&out, &ListTrackersOptions{TrackingCodes: []string{"A", "B"}, PageSize: 1}, &ExportOptions{
+		Format:  ExportCSV,
+		Columns: []string{"ID", "TrackingCode"},
+	})
+	require.NoError(err)
+	assert.Equal(2, count)
+	assert.Equal(2, *calls)
+	assert.Equal("ID,TrackingCode\ntrk_3,B\ntrk_1,A\n", out.String())
+}
+
+func (c *ClientTests) TestExportInvalidOptions() {
+	assert := c.Assert()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{"shipments": []}`, nil))
+
+	var invalidObjectError *InvalidObjectError
+	_, err := client.ExportShipments(&bytes.Buffer{}, nil, &ExportOptions{Columns: []string{"ToAddress.Nope"}})
+	assert.True(errors.As(err, &invalidObjectError))
+
+	_, err = client.ExportShipments(&bytes.Buffer{}, nil, &ExportOptions{Columns: []string{"Rates.Rate"}})
+	assert.True(errors.As(err, &invalidObjectError))
+
+	_, err = client.ExportShipments(&bytes.Buffer{}, nil, &ExportOptions{Format: "xlsx"})
+	assert.True(errors.As(err, &invalidObjectError))
+
+	assert.Equal(0, *calls)
+}
@@ -1,11 +1,15 @@
 package easypost
 
 import (
+	"bytes"
 	"context"
-	"github.com/google/uuid"
 	"io"
+	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // HookEvent is the base type for all hook events
@@ -20,13 +24,20 @@ type HookEventSubscriber struct {
 
 // RequestHookEvent is the data passed to a RequestHookEventSubscriberCallback function
 type RequestHookEvent struct {
-	HookEvent        // implements HookEvent
-	Method           string
-	Url              *url.URL
-	RequestBody      io.ReadCloser
-	Headers          map[string][]string
-	RequestTimestamp time.Time
-	Id               uuid.UUID
+	HookEvent // implements HookEvent
+	Method    string
+	Url       *url.URL
+	// RequestBody is a copy of the request body that can be read without affecting the request, or nil if the request
+	// has no body. Each subscriber receives its own copy, truncated to the Hooks' MaxBodyBytes.
+	RequestBody io.ReadCloser
+	// RequestBodyTruncated is true if RequestBody was truncated to the Hooks' MaxBodyBytes.
+	RequestBodyTruncated bool
+	Headers              map[string][]string
+	RequestTimestamp     time.Time
+	// Id is shared by the request and response events of every attempt of the same API call.
+	Id uuid.UUID
+	// Attempt is the 1-based number of the attempt being made when the client retries an API call.
+	Attempt int
 }
 
 // RequestHookEventSubscriberCallback is the type of the callback function executed by an RequestHookEventSubscriber
@@ -40,23 +51,32 @@ type RequestHookEventSubscriber struct {
 
 // Execute executes the RequestHookEventSubscriberCallback function of the RequestHookEventSubscriber
 func (s RequestHookEventSubscriber) Execute(ctx context.Context, event RequestHookEvent) {
-	err := s.Callback(ctx, event)
-	if err != nil {
-		return
-	}
+	_ = s.ExecuteWithError(ctx, event)
+}
+
+// ExecuteWithError executes the RequestHookEventSubscriberCallback function of the RequestHookEventSubscriber, returning its error
+func (s RequestHookEventSubscriber) ExecuteWithError(ctx context.Context, event RequestHookEvent) error {
+	return s.Callback(ctx, event)
 }
 
 // ResponseHookEvent is the data passed to a ResponseHookEventSubscriberCallback function
 type ResponseHookEvent struct {
-	HookEvent         // implements HookEvent
-	HttpStatus        int
-	Method            string
-	Url               *url.URL
-	Headers           map[string][]string
-	ResponseBody      io.ReadCloser
-	RequestTimestamp  time.Time
-	ResponseTimestamp time.Time
-	Id                uuid.UUID
+	HookEvent  // implements HookEvent
+	HttpStatus int
+	Method     string
+	Url        *url.URL
+	Headers    map[string][]string
+	// ResponseBody is a copy of the response body that can be read without affecting the response, or nil if no
+	// response was received. Each subscriber receives its own copy, truncated to the Hooks' MaxBodyBytes.
+	ResponseBody io.ReadCloser
+	// ResponseBodyTruncated is true if ResponseBody was truncated to the Hooks' MaxBodyBytes.
+	ResponseBodyTruncated bool
+	RequestTimestamp      time.Time
+	ResponseTimestamp     time.Time
+	// Id is shared by the request and response events of every attempt of the same API call.
+	Id uuid.UUID
+	// Attempt is the 1-based number of the attempt that produced this response.
+	Attempt int
 }
 
 // ResponseHookEventSubscriberCallback is the type of the callback function executed by an ResponseHookEventSubscriber
@@ -70,30 +90,265 @@ type ResponseHookEventSubscriber struct {
 
 // Execute executes the ResponseHookEventSubscriberCallback function of the ResponseHookEventSubscriber
 func (s ResponseHookEventSubscriber) Execute(ctx context.Context, event ResponseHookEvent) {
-	err := s.Callback(ctx, event)
+	_ = s.ExecuteWithError(ctx, event)
+}
+
+// ExecuteWithError executes the ResponseHookEventSubscriberCallback function of the ResponseHookEventSubscriber, returning its error
+func (s ResponseHookEventSubscriber) ExecuteWithError(ctx context.Context, event ResponseHookEvent) error {
+	return s.Callback(ctx, event)
+}
+
+// CircuitBreakerHookEvent is the data passed to a CircuitBreakerHookEventSubscriberCallback function
+type CircuitBreakerHookEvent struct {
+	HookEvent           // implements HookEvent
+	From                CircuitBreakerState
+	To                  CircuitBreakerState
+	ConsecutiveFailures int
+	Timestamp           time.Time
+}
+
+// CircuitBreakerHookEventSubscriberCallback is the type of the callback function executed by an CircuitBreakerHookEventSubscriber
+type CircuitBreakerHookEventSubscriberCallback func(ctx context.Context, event CircuitBreakerHookEvent) error
+
+// CircuitBreakerHookEventSubscriber is a HookEventSubscriber that executes a CircuitBreakerHookEventSubscriberCallback function when the client's CircuitBreaker changes state
+type CircuitBreakerHookEventSubscriber struct {
+	HookEventSubscriber // implements HookEventSubscriber
+	Callback            CircuitBreakerHookEventSubscriberCallback
+}
+
+// Execute executes the CircuitBreakerHookEventSubscriberCallback function of the CircuitBreakerHookEventSubscriber
+func (s CircuitBreakerHookEventSubscriber) Execute(ctx context.Context, event CircuitBreakerHookEvent) {
+	_ = s.ExecuteWithError(ctx, event)
+}
+
+// ExecuteWithError executes the CircuitBreakerHookEventSubscriberCallback function of the CircuitBreakerHookEventSubscriber, returning its error
+func (s CircuitBreakerHookEventSubscriber) ExecuteWithError(ctx context.Context, event CircuitBreakerHookEvent) error {
+	return s.Callback(ctx, event)
+}
+
+// HooksMiddleware returns a Middleware that executes the request hooks of the given Hooks before calling the next
+// Handler, and the response hooks once it returns.
+//
+// If the Hooks' AbortOnRequestHookError is set, the first request hook returning an error aborts the call with a
+// RequestHookError: the next Handler and the response hooks are not called.
+func HooksMiddleware(hooks *Hooks) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			attempt := requestAttemptFromContext(ctx)
+			requestSubscribers := hooks.requestSubscribers()
+
+			var requestBody []byte
+			if len(requestSubscribers) > 0 && hooks.maxBodyBytes() >= 0 {
+				var err error
+				if requestBody, err = bufferRequestBody(req); err != nil {
+					return nil, err
+				}
+			}
+
+			// prepare and execute request hook(s)
+			requestTimestamp := time.Now()
+			requestEvent := &RequestHookEvent{
+				Method:           req.Method,
+				Url:              req.URL,
+				Headers:          req.Header.Clone(),
+				RequestTimestamp: requestTimestamp,
+				Id:               attempt.id,
+				Attempt:          attempt.attempt,
+			}
+
+			// loop over each request hook and execute it, each with its own copy of the body
+			for _, hook := range requestSubscribers {
+				requestEvent.RequestBody, requestEvent.RequestBodyTruncated = hooks.bodyCopy(requestBody)
+				if err := hook.ExecuteWithError(ctx, *requestEvent); err != nil && hooks.AbortOnRequestHookError {
+					return nil, newRequestHookError(hook.ID, err)
+				}
+			}
+
+			res, err := next(req)
+
+			responseSubscribers := hooks.responseSubscribers()
+			if len(responseSubscribers) == 0 {
+				return res, err
+			}
+
+			var responseBody []byte
+			if err == nil && res.Body != nil && hooks.maxBodyBytes() >= 0 {
+				if responseBody, err = bufferResponseBody(res); err != nil {
+					res = nil
+				}
+			}
+
+			// prepare and execute response hook(s)
+			responseEvent := &ResponseHookEvent{
+				HttpStatus:        0,
+				Method:            req.Method,
+				Url:               req.URL,
+				Headers:           nil,
+				RequestTimestamp:  requestTimestamp,
+				ResponseTimestamp: time.Now(),
+				Id:                attempt.id,
+				Attempt:           attempt.attempt,
+			}
+			if err == nil {
+				responseEvent.HttpStatus = res.StatusCode
+				responseEvent.Headers = res.Header.Clone()
+			}
+
+			// loop over each response hook and execute it, each with its own copy of the body
+			for _, hook := range responseSubscribers {
+				if err == nil {
+					responseEvent.ResponseBody, responseEvent.ResponseBodyTruncated = hooks.bodyCopy(responseBody)
+				}
+				hook.Execute(ctx, *responseEvent)
+			}
+
+			return res, err
+		}
+	}
+}
+
+// bufferRequestBody returns the content of the request body, leaving the request able to send it.
+func bufferRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = body.Close() }()
+		return io.ReadAll(body)
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(buf))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	return buf, nil
+}
+
+// bufferResponseBody reads the response body into memory, replacing it so it can still be read by the caller.
+func bufferResponseBody(res *http.Response) ([]byte, error) {
+	defer func() { _ = res.Body.Close() }()
+	buf, err := io.ReadAll(res.Body)
 	if err != nil {
-		return
+		return nil, newTransportError(err)
 	}
+	res.Body = io.NopCloser(bytes.NewReader(buf))
+	return buf, nil
 }
 
-// Hooks is a collection of HookEventSubscriber instances for various hooks available in the client
+// defaultHookMaxBodyBytes is the default size cap of the bodies copied into hook events.
+const defaultHookMaxBodyBytes = 1 << 20
+
+// Hooks is a collection of HookEventSubscriber instances for various hooks available in the client.
+// The Add/Remove methods are safe for concurrent use, including while API calls are in flight.
 type Hooks struct {
-	RequestHookEventSubscriptions  []RequestHookEventSubscriber // these are directly accessible by the user, but should not be modified directly (use Add/Remove methods)
-	ResponseHookEventSubscriptions []ResponseHookEventSubscriber
+	RequestHookEventSubscriptions        []RequestHookEventSubscriber // these are directly accessible by the user, but should not be modified directly (use Add/Remove methods)
+	ResponseHookEventSubscriptions       []ResponseHookEventSubscriber
+	CircuitBreakerHookEventSubscriptions []CircuitBreakerHookEventSubscriber
+	// MaxBodyBytes caps the size (in bytes) of the request and response bodies copied into hook events, larger bodies
+	// being truncated. If zero, a default of 1 MiB will be used. If negative, bodies are not copied into hook events.
+	MaxBodyBytes int
+	// AbortOnRequestHookError makes an error returned by a RequestHookEventSubscriberCallback abort the API call with a
+	// RequestHookError before it is sent. By default, errors returned by hook callbacks are ignored.
+	AbortOnRequestHookError bool
+
+	// mu is held behind a pointer so that Hooks, and the Client holding them, can be copied by value. Copies made after
+	// first use share it.
+	mu *sync.RWMutex
+}
+
+// lazyInitMu guards the lazy initialization of the state held behind pointers by types that are usually created as
+// struct literals, such as Hooks, so that they can still be copied by value.
+var lazyInitMu sync.Mutex
+
+// lock returns the lock guarding the subscriptions, creating it on first use.
+func (h *Hooks) lock() *sync.RWMutex {
+	lazyInitMu.Lock()
+	defer lazyInitMu.Unlock()
+	if h.mu == nil {
+		h.mu = &sync.RWMutex{}
+	}
+	return h.mu
+}
+
+func (h *Hooks) maxBodyBytes() int {
+	if h.MaxBodyBytes == 0 {
+		return defaultHookMaxBodyBytes
+	}
+	return h.MaxBodyBytes
+}
+
+// bodyCopy returns a reader over a copy of body capped to MaxBodyBytes, and whether the copy was truncated.
+func (h *Hooks) bodyCopy(body []byte) (io.ReadCloser, bool) {
+	maxBodyBytes := h.maxBodyBytes()
+	if body == nil || maxBodyBytes < 0 {
+		return nil, false
+	}
+	truncated := len(body) > maxBodyBytes
+	if truncated {
+		body = body[:maxBodyBytes]
+	}
+	return io.NopCloser(bytes.NewReader(body)), truncated
+}
+
+// requestSubscribers returns a snapshot of the RequestHookEventSubscriber instances
+func (h *Hooks) requestSubscribers() []RequestHookEventSubscriber {
+	mu := h.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]RequestHookEventSubscriber(nil), h.RequestHookEventSubscriptions...)
+}
+
+// responseSubscribers returns a snapshot of the ResponseHookEventSubscriber instances
+func (h *Hooks) responseSubscribers() []ResponseHookEventSubscriber {
+	mu := h.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]ResponseHookEventSubscriber(nil), h.ResponseHookEventSubscriptions...)
+}
+
+// circuitBreakerSubscribers returns a snapshot of the CircuitBreakerHookEventSubscriber instances
+func (h *Hooks) circuitBreakerSubscribers() []CircuitBreakerHookEventSubscriber {
+	mu := h.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]CircuitBreakerHookEventSubscriber(nil), h.CircuitBreakerHookEventSubscriptions...)
 }
 
 // AddRequestEventSubscriber adds a RequestHookEventSubscriber to the Hooks instance to be executed when a RequestHookEvent is fired
 func (h *Hooks) AddRequestEventSubscriber(subscriber RequestHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	h.RequestHookEventSubscriptions = append(h.RequestHookEventSubscriptions, subscriber)
 }
 
 // AddResponseEventSubscriber adds a ResponseHookEventSubscriber to the Hooks instance to be executed when a ResponseHookEvent is fired
 func (h *Hooks) AddResponseEventSubscriber(subscriber ResponseHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	h.ResponseHookEventSubscriptions = append(h.ResponseHookEventSubscriptions, subscriber)
 }
 
 // RemoveRequestEventSubscriber removes a RequestHookEventSubscriber from the Hooks instance
 func (h *Hooks) RemoveRequestEventSubscriber(subscriber RequestHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	for i, sub := range h.RequestHookEventSubscriptions {
 		if sub.ID == subscriber.ID {
 			h.RequestHookEventSubscriptions = append(h.RequestHookEventSubscriptions[:i], h.RequestHookEventSubscriptions[i+1:]...)
@@ -104,6 +359,10 @@ func (h *Hooks) RemoveRequestEventSubscriber(subscriber RequestHookEventSubscrib
 
 // RemoveResponseEventSubscriber removes a ResponseHookEventSubscriber from the Hooks instance
 func (h *Hooks) RemoveResponseEventSubscriber(subscriber ResponseHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	for i, sub := range h.ResponseHookEventSubscriptions {
 		if sub.ID == subscriber.ID {
 			h.ResponseHookEventSubscriptions = append(h.ResponseHookEventSubscriptions[:i], h.ResponseHookEventSubscriptions[i+1:]...)
@@ -111,3 +370,33 @@ func (h *Hooks) RemoveResponseEventSubscriber(subscriber ResponseHookEventSubscr
 		}
 	}
 }
+
+// AddCircuitBreakerEventSubscriber adds a CircuitBreakerHookEventSubscriber to the Hooks instance to be executed when a CircuitBreakerHookEvent is fired
+func (h *Hooks) AddCircuitBreakerEventSubscriber(subscriber CircuitBreakerHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	h.CircuitBreakerHookEventSubscriptions = append(h.CircuitBreakerHookEventSubscriptions, subscriber)
+}
+
+// RemoveCircuitBreakerEventSubscriber removes a CircuitBreakerHookEventSubscriber from the Hooks instance
+func (h *Hooks) RemoveCircuitBreakerEventSubscriber(subscriber CircuitBreakerHookEventSubscriber) {
+	mu := h.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, sub := range h.CircuitBreakerHookEventSubscriptions {
+		if sub.ID == subscriber.ID {
+			h.CircuitBreakerHookEventSubscriptions = append(h.CircuitBreakerHookEventSubscriptions[:i], h.CircuitBreakerHookEventSubscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// executeCircuitBreakerHooks executes each CircuitBreakerHookEventSubscriber with the given event
+func (h *Hooks) executeCircuitBreakerHooks(ctx context.Context, event CircuitBreakerHookEvent) {
+	for _, hook := range h.circuitBreakerSubscribers() {
+		hook.Execute(ctx, event)
+	}
+}
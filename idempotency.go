@@ -0,0 +1,82 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the HTTP header used to make a request safe to repeat.
+// The API will not perform the same operation twice for requests sharing the same idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// NewIdempotencyKey returns a new random idempotency key.
+func NewIdempotencyKey() string {
+	return uuid.NewString()
+}
+
+// ContextWithIdempotencyKey returns a copy of ctx that attaches the given idempotency key to the request made by any
+// *WithContext method it is passed to. Reuse the same key when repeating a call that may have already succeeded
+// (e.g. after a timeout) so that it is not performed twice.
+//
+// The key is bound to the first API call it is sent with: passing ctx to a different call (another method or path)
+// fails with an IdempotencyKeyReuseError instead of sending the key again, since the API would treat that call as a
+// repeat of the first one.
+//
+// It is a shorthand for ContextWithRequestOptions with WithIdempotencyKey, so the key set last on ctx, by either of
+// them, is the one sent.
+//
+//	ctx := easypost.ContextWithIdempotencyKey(context.Background(), order.ID)
+//	shipment, err := client.BuyShipmentWithContext(ctx, shipmentID, rate, "")
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return ContextWithRequestOptions(ctx, WithIdempotencyKey(key))
+}
+
+// ContextWithNewIdempotencyKey returns a copy of ctx with a newly generated idempotency key attached, along with the
+// generated key so that it can be stored and reused if the call needs to be repeated.
+func ContextWithNewIdempotencyKey(ctx context.Context) (context.Context, string) {
+	key := NewIdempotencyKey()
+	return ContextWithIdempotencyKey(ctx, key), key
+}
+
+// IdempotencyKeyFromContext returns the idempotency key attached to ctx, if any.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	return requestOptionsFromContext(ctx).idempotencyKey
+}
+
+// idempotencyKey returns the idempotency key to send with a request, generating one for POST requests if the client
+// is configured to do so.
+func (c *Client) idempotencyKey(ctx context.Context, method string) string {
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		return key
+	}
+	if c.AutoIdempotencyKeys && method == http.MethodPost {
+		return NewIdempotencyKey()
+	}
+	return ""
+}
+
+// idempotencyKeyCall records the API call an idempotency key attached with WithIdempotencyKey was first sent with.
+type idempotencyKeyCall struct {
+	mu   sync.Mutex
+	call string
+}
+
+// bind binds the key to the given API call on first use, and returns an IdempotencyKeyReuseError if it was already
+// bound to another one.
+func (k *idempotencyKeyCall) bind(method, path string) error {
+	call := method + " " + path
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.call == "" {
+		k.call = call
+	} else if k.call != call {
+		return newIdempotencyKeyReuseError(k.call)
+	}
+	return nil
+}
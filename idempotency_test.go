@@ -0,0 +1,119 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestIdempotencyKeyFromContext() {
+	assert, require := c.Assert(), c.Require()
+
+	var keys []string
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, nil), nil
+	})
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order_123")
+	_, err := client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	_, err = client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	assert.Equal([]string{"order_123", ""}, keys)
+}
+
+func (c *ClientTests) TestIdempotencyKeyReusedAcrossRetries() {
+	assert, require := c.Assert(), c.Require()
+
+	var keys []string
+	record := func(statusCode int) func(req *http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+			return scriptedResponse(statusCode, `{}`, nil), nil
+		}
+	}
+	client, calls := c.ScriptedClient(record(503), record(200))
+	client.AutoIdempotencyKeys = true
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+	_, err := client.CreateShipment(&Shipment{})
+	require.NoError(err)
+
+	// a POST carrying an idempotency key is retried with the same key
+	assert.Equal(2, *calls)
+	assert.NotEmpty(keys[0])
+	assert.Equal(keys[0], keys[1])
+}
+
+func (c *ClientTests) TestAutoIdempotencyKeysOnlyForPost() {
+	assert, require := c.Assert(), c.Require()
+
+	var keys []string
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		return scriptedResponse(200, `{}`, nil), nil
+	})
+	client.AutoIdempotencyKeys = true
+
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	_, err = client.CreateBatch()
+	require.NoError(err)
+	_, err = client.CreateBatch()
+	require.NoError(err)
+
+	assert.Equal(3, len(keys))
+	assert.Empty(keys[0])
+	assert.NotEmpty(keys[1])
+	assert.NotEqual(keys[1], keys[2])
+}
+
+func (c *ClientTests) TestIdempotencyKeyOnError() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(422, `{"error": {"code": "SHIPMENT.POSTAGE.FAILURE", "message": "failed"}}`, nil))
+
+	ctx, key := ContextWithNewIdempotencyKey(context.Background())
+	_, err := client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.Error(err)
+
+	var invalidRequestError *InvalidRequestError
+	require.True(errors.As(err, &invalidRequestError))
+	assert.Equal(key, invalidRequestError.IdempotencyKey)
+	assert.Equal(key, IdempotencyKeyFromContext(ctx))
+}
+
+func (c *ClientTests) TestIdempotencyKeyBoundToFirstCall() {
+	assert, require := c.Assert(), c.Require()
+
+	var keys []string
+	client, calls := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, nil), nil
+	})
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order_123")
+	_, err := client.CreateShipmentWithContext(ctx, &Shipment{})
+	require.NoError(err)
+
+	// the same call can be repeated with the key, but another call is refused before being sent
+	_, err = client.CreateShipmentWithContext(ctx, &Shipment{})
+	require.NoError(err)
+	_, err = client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	var reuseError *IdempotencyKeyReuseError
+	require.True(errors.As(err, &reuseError))
+	assert.Contains(reuseError.Message, "POST shipments")
+
+	assert.Equal(2, *calls)
+	assert.Equal([]string{"order_123", "order_123"}, keys)
+
+	// a fresh key can be attached for the next call
+	ctx = ContextWithIdempotencyKey(ctx, "buy-order_123")
+	_, err = client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	assert.Equal("buy-order_123", keys[2])
+}
@@ -66,6 +66,12 @@ func (c *Client) CreateInsuranceWithContext(ctx context.Context, in *Insurance)
 	return
 }
 
+// CreateInsuranceWithRequestOptions performs the same operation as CreateInsuranceWithContext,
+// applying the given request options to this call only.
+func (c *Client) CreateInsuranceWithRequestOptions(ctx context.Context, in *Insurance, opts ...RequestOption) (out *Insurance, err error) {
+	return c.CreateInsuranceWithContext(ContextWithRequestOptions(ctx, opts...), in)
+}
+
 // ListInsurances provides a paginated result of Insurance objects.
 func (c *Client) ListInsurances(opts *ListOptions) (out *ListInsurancesResult, err error) {
 	return c.ListInsurancesWithContext(context.Background(), opts)
@@ -109,6 +115,97 @@ func (c *Client) GetNextInsurancePageWithPageSizeWithContext(ctx context.Context
 	return c.ListInsurancesWithContext(ctx, params)
 }
 
+// InsuranceIterator iterates over a paginated collection of insurances. See IterateInsurances.
+type InsuranceIterator struct {
+	*pageIterator
+}
+
+// Insurance returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *InsuranceIterator) Insurance() *Insurance {
+	insurance, _ := it.current.(*Insurance)
+	return insurance
+}
+
+// IterateInsurances returns an iterator over the insurances matching opts, fetching the pages of the collection lazily
+// as the iteration goes.
+//
+//	it := c.IterateInsurances(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Insurance().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateInsurances(opts *ListOptions, iterOpts *IteratorOptions) *InsuranceIterator {
+	return c.IterateInsurancesWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateInsurancesWithContext performs the same operation as IterateInsurances, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateInsurancesWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *InsuranceIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListInsurancesWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Insurances))
+		for i, insurance := range page.Insurances {
+			items[i] = insurance
+		}
+		return items, page.HasMore, nil
+	}
+	return &InsuranceIterator{newListIterator(ctx, "insurances", CursorBeforeID, &filters, iterOpts, list)}
+}
+
+// ListInsurancesInDateRange returns every insurance created between the StartDateTime and EndDateTime of opts, newest
+// first. The range is split into windows that are paged through concurrently as configured by rangeOpts, and insurances
+// returned by more than one window are only included once. The other filters of opts apply to every window.
+func (c *Client) ListInsurancesInDateRange(opts *ListOptions, rangeOpts *DateRangeOptions) (out []*Insurance, err error) {
+	return c.ListInsurancesInDateRangeWithContext(context.Background(), opts, rangeOpts)
+}
+
+// ListInsurancesInDateRangeWithContext performs the same operation as ListInsurancesInDateRange, but
+// allows specifying a context that can interrupt the requests.
+func (c *Client) ListInsurancesInDateRangeWithContext(ctx context.Context, opts *ListOptions, rangeOpts *DateRangeOptions) (out []*Insurance, err error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	fetch := func(ctx context.Context, start, end *DateTime) ([]interface{}, error) {
+		windowOpts := *opts
+		windowOpts.BeforeID, windowOpts.AfterID = "", ""
+		windowOpts.StartDateTime, windowOpts.EndDateTime = start, end
+
+		var items []interface{}
+		it := c.IterateInsurancesWithContext(ctx, &windowOpts, nil)
+		for it.Next() {
+			items = append(items, it.Insurance())
+		}
+		return items, it.Err()
+	}
+	idOf := func(item interface{}) string {
+		if insurance := item.(*Insurance); insurance != nil {
+			return insurance.ID
+		}
+		return ""
+	}
+
+	items, err := fetchDateRange(ctx, opts.StartDateTime, opts.EndDateTime, rangeOpts, fetch, idOf)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]*Insurance, len(items))
+	for i, item := range items {
+		out[i] = item.(*Insurance)
+	}
+	return out, nil
+}
+
 // GetInsurance returns the Insurance object with the given ID or reference.
 func (c *Client) GetInsurance(insuranceID string) (out *Insurance, err error) {
 	return c.GetInsuranceWithContext(context.Background(), insuranceID)
@@ -0,0 +1,193 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// IteratorOptions limits the items returned by an iterator over a paginated collection, such as the one returned by
+// IterateShipments.
+type IteratorOptions struct {
+	// Limit is the maximum number of items returned. If zero, every item is returned.
+	Limit int
+	// CreatedAfter stops the iteration at the first item created at or before this time. Collections are returned
+	// newest first, so no later item would be newer. If zero, the StartDateTime of the list options is used, if any.
+	// Child users and referral customers have no creation time and are not affected.
+	CreatedAfter time.Time
+	// ResumeFrom continues the iteration from a checkpoint returned by the Checkpoint method of an iterator over the
+	// same collection, in place of the list options. Limit only counts the items returned after the checkpoint.
+	ResumeFrom *PaginationCheckpoint
+}
+
+// listPage lists the page of a paginated collection selected by opts, returning its items and whether more follow.
+type listPage func(ctx context.Context, opts paginatedListOptions) (items []interface{}, hasMore bool, err error)
+
+// pageIterator walks the pages of a paginated collection lazily, one item at a time. The iterator of each resource
+// embeds it and adds a typed accessor to the current item.
+type pageIterator struct {
+	ctx          context.Context
+	fetch        func(ctx context.Context) ([]interface{}, error)
+	limit        int
+	createdAfter time.Time
+	checkpoint   PaginationCheckpoint
+
+	items   []interface{}
+	current interface{}
+	count   int
+	done    bool
+	err     error
+}
+
+// newListIterator returns an iterator over the collection of the given resource, listed one page at a time by list.
+// opts must be a copy of the list options of the caller: the iterator moves its cursor along the collection, so that
+// every page is requested with the same filters. If iterOpts resumes from a checkpoint, opts is replaced by the list
+// options of the checkpoint.
+func newListIterator(ctx context.Context, resource string, cursor PaginationCursor, opts paginatedListOptions, iterOpts *IteratorOptions, list listPage) *pageIterator {
+	checkpoint, err := newPaginationCheckpoint(resource, cursor, opts, iterOpts)
+	if err == nil && iterOpts != nil && iterOpts.ResumeFrom != nil {
+		err = checkpoint.restore(opts)
+	}
+
+	exhausted := false
+	fetch := func(ctx context.Context) ([]interface{}, error) {
+		if exhausted {
+			return nil, newEndOfPaginationError()
+		}
+		items, hasMore, err := list(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !hasMore || len(items) == 0 {
+			exhausted = true
+		} else {
+			cursor.next(opts, itemID(items[len(items)-1]))
+		}
+		return items, nil
+	}
+
+	it := newPageIterator(ctx, iterOpts, checkpoint, fetch)
+	if err != nil {
+		it.stop(err)
+	}
+	return it
+}
+
+func newPageIterator(ctx context.Context, options *IteratorOptions, checkpoint PaginationCheckpoint, fetch func(ctx context.Context) ([]interface{}, error)) *pageIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if options == nil {
+		options = &IteratorOptions{}
+	}
+
+	it := &pageIterator{
+		ctx:          ctx,
+		fetch:        fetch,
+		limit:        options.Limit,
+		createdAfter: options.CreatedAfter,
+		checkpoint:   checkpoint,
+		done:         checkpoint.Done,
+	}
+	if it.createdAfter.IsZero() && checkpoint.StartDateTime != nil {
+		it.createdAfter = time.Time(*checkpoint.StartDateTime)
+	}
+	return it
+}
+
+// itemField returns the field of an item of a collection with the given name, if the item has one.
+func itemField(item interface{}, name string) reflect.Value {
+	v := reflect.ValueOf(item)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.Elem().FieldByName(name)
+}
+
+// itemID returns the ID of an item of a collection.
+func itemID(item interface{}) string {
+	if id := itemField(item, "ID"); id.IsValid() && id.Kind() == reflect.String {
+		return id.String()
+	}
+	return ""
+}
+
+// itemCreatedAt returns the creation time of an item of a collection, or nil if it has none (e.g. child users).
+func itemCreatedAt(item interface{}) *DateTime {
+	if createdAt := itemField(item, "CreatedAt"); createdAt.IsValid() && createdAt.CanInterface() {
+		value, _ := createdAt.Interface().(*DateTime)
+		return value
+	}
+	return nil
+}
+
+// Next advances the iterator to the next item, fetching the next page of the collection when needed. It returns false
+// once there are no items left, a limit set by IteratorOptions is reached, the context is done or an error occurred.
+// Err returns the error, if any.
+func (it *pageIterator) Next() bool {
+	for !it.done {
+		if err := it.ctx.Err(); err != nil {
+			return it.stop(err)
+		}
+		if it.limit > 0 && it.count >= it.limit {
+			return it.stop(nil)
+		}
+
+		if len(it.items) > 0 {
+			item := it.items[0]
+			it.items = it.items[1:]
+			if !it.createdAfter.IsZero() {
+				if createdAt := itemCreatedAt(item); createdAt != nil && !time.Time(*createdAt).After(it.createdAfter) {
+					return it.finish()
+				}
+			}
+			it.current = item
+			it.count++
+			it.checkpoint.LastID = itemID(item)
+			return true
+		}
+
+		items, err := it.fetch(it.ctx)
+		var endOfPaginationError *EndOfPaginationError
+		if errors.As(err, &endOfPaginationError) {
+			return it.finish()
+		}
+		if err != nil {
+			return it.stop(err)
+		}
+		if len(items) == 0 {
+			return it.finish()
+		}
+		it.items = items
+	}
+	return false
+}
+
+// finish ends the iteration at the end of the collection or its date window.
+func (it *pageIterator) finish() bool {
+	it.checkpoint.Done = true
+	return it.stop(nil)
+}
+
+// stop ends the iteration with the given error, which is nil if it ended normally.
+func (it *pageIterator) stop(err error) bool {
+	it.done = true
+	it.err = err
+	it.current = nil
+	it.items = nil
+	return false
+}
+
+// Err returns the error that stopped the iteration, if any. Reaching the end of the collection or a limit set by
+// IteratorOptions is not an error.
+func (it *pageIterator) Err() error {
+	return it.err
+}
+
+// Checkpoint returns the position of the iterator, right after the last item returned by Next. It can be persisted and
+// passed to IteratorOptions.ResumeFrom to continue the iteration later, for instance after a crash.
+func (it *pageIterator) Checkpoint() *PaginationCheckpoint {
+	checkpoint := it.checkpoint
+	return &checkpoint
+}
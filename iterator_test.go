@@ -0,0 +1,147 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestIterateShipmentsPagesLazily() {
+	assert, require := c.Assert(), c.Require()
+
+	var queries []string
+	pages := []string{
+		`{"shipments": [{"id": "shp_1"}, {"id": "shp_2"}], "has_more": true}`,
+		`{"shipments": [{"id": "shp_3"}], "has_more": false}`,
+	}
+	client, calls := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return scriptedResponse(200, pages[len(queries)-1], nil), nil
+	})
+
+	it := client.IterateShipments(&ListShipmentsOptions{PageSize: 2, Purchased: BoolPtr(true)}, nil)
+	assert.Nil(it.Shipment())
+	assert.Equal(0, *calls)
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Shipment().ID)
+		if len(ids) == 1 {
+			// the next page is only fetched once the first one is exhausted
+			assert.Equal(1, *calls)
+		}
+	}
+	require.NoError(it.Err())
+	assert.Nil(it.Shipment())
+	assert.False(it.Next())
+
+	assert.Equal([]string{"shp_1", "shp_2", "shp_3"}, ids)
+	require.Len(queries, 2)
+	assert.Equal("page_size=2&purchased=true", queries[0])
+	assert.Equal("before_id=shp_2&page_size=2&purchased=true", queries[1])
+}
+
+func (c *ClientTests) TestIteratorStopsAtLimitAndDateBoundary() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{"trackers": [
+		{"id": "trk_1", "created_at": "2026-03-03T00:00:00Z"},
+		{"id": "trk_2", "created_at": "2026-03-02T00:00:00Z"},
+		{"id": "trk_3", "created_at": "2026-03-01T00:00:00Z"}
+	], "has_more": true}`, nil))
+
+	it := client.IterateTrackers(nil, &IteratorOptions{Limit: 2})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(it.Err())
+	assert.Equal(2, count)
+	assert.Equal(1, *calls)
+
+	start := DateTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	it = client.IterateTrackers(&ListTrackersOptions{StartDateTime: &start}, nil)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Tracker().ID)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"trk_1", "trk_2"}, ids)
+	assert.Equal(2, *calls)
+}
+
+func (c *ClientTests) TestIterateChildUsersUsesAfterID() {
+	assert, require := c.Assert(), c.Require()
+
+	var queries []string
+	pages := []string{
+		`{"children": [{"id": "user_1"}], "has_more": true}`,
+		`{"children": [], "has_more": false}`,
+	}
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return scriptedResponse(200, pages[len(queries)-1], nil), nil
+	})
+
+	it := client.IterateChildUsers(nil, nil)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.ChildUser().ID)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"user_1"}, ids)
+	assert.Equal([]string{"", "after_id=user_1"}, queries)
+}
+
+func (c *ClientTests) TestIteratorSurfacesErrors() {
+	assert := c.Assert()
+
+	client, _ := c.ScriptedClient(
+		respondWith(200, `{"insurances": [{"id": "ins_1"}], "has_more": true}`, nil),
+		respondWith(401, `{"error": {"code": "APIKEY.INACTIVE", "message": "inactive"}}`, nil),
+	)
+
+	it := client.IterateInsurances(nil, nil)
+	assert.True(it.Next())
+	assert.False(it.Next())
+	var unauthorizedError *UnauthorizedError
+	assert.True(errors.As(it.Err(), &unauthorizedError))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = client.IterateInsurancesWithContext(ctx, nil, nil)
+	assert.False(it.Next())
+	assert.True(errors.Is(it.Err(), context.Canceled))
+}
+
+func (c *ClientTests) TestIteratorKeepsFiltersOnEveryPage() {
+	assert, require := c.Assert(), c.Require()
+
+	var queries []string
+	pages := []string{
+		`{"trackers": [{"id": "trk_1"}], "has_more": true}`,
+		`{"trackers": [{"id": "trk_2"}], "has_more": true}`,
+		`{"trackers": [], "has_more": false}`,
+	}
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return scriptedResponse(200, pages[len(queries)-1], nil), nil
+	})
+
+	opts := &ListTrackersOptions{TrackingCodes: []string{"A", "B"}, Carrier: "USPS", PageSize: 1}
+	it := client.IterateTrackers(opts, nil)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Tracker().ID)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"trk_1", "trk_2"}, ids)
+	assert.Equal([]string{
+		"carrier=USPS&page_size=1&tracking_codes=A&tracking_codes=B",
+		"before_id=trk_1&carrier=USPS&page_size=1&tracking_codes=A&tracking_codes=B",
+		"before_id=trk_2&carrier=USPS&page_size=1&tracking_codes=A&tracking_codes=B",
+	}, queries)
+	// the options of the caller are left as is
+	assert.Empty(opts.BeforeID)
+}
@@ -2,11 +2,11 @@ package easypost
 
 // ListOptions is used to specify query parameters for listing EasyPost objects.
 type ListOptions struct {
-	BeforeID      string    `url:"before_id,omitempty"`
-	AfterID       string    `url:"after_id,omitempty"`
-	StartDateTime *DateTime `url:"start_datetime,omitempty"`
-	EndDateTime   *DateTime `url:"end_datetime,omitempty"`
-	PageSize      int       `url:"page_size,omitempty"`
+	BeforeID      string    `json:"before_id,omitempty" url:"before_id,omitempty"`
+	AfterID       string    `json:"after_id,omitempty" url:"after_id,omitempty"`
+	StartDateTime *DateTime `json:"start_datetime,omitempty" url:"start_datetime,omitempty"`
+	EndDateTime   *DateTime `json:"end_datetime,omitempty" url:"end_datetime,omitempty"`
+	PageSize      int       `json:"page_size,omitempty" url:"page_size,omitempty"`
 }
 
 // nextPageParameters returns the next page of a paginated collection.
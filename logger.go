@@ -0,0 +1,89 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader is the HTTP header carrying the ID the API assigned to a request. Quote it when contacting EasyPost
+// support about a specific API call.
+const RequestIDHeader = "X-Ep-Request-Uuid"
+
+// Logger is the interface used by a Client to log its API calls. It is satisfied by *slog.Logger, and can easily be
+// implemented on top of other structured logging libraries.
+//
+// The args are alternating keys and values, as accepted by *slog.Logger.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// LoggingMiddleware returns a Middleware that logs each attempt of an API call to logger, with its method, path,
+// status code, duration, attempt number and the API's request ID.
+//
+// Successful calls are logged at the info level, calls failing with a 4xx status code at the warn level, and calls
+// failing with a 5xx status code or a transport error at the error level. If logBodies is true, the request and
+// response bodies are included, after removing the sensitive fields listed by redactor (DefaultRedactor if nil).
+func LoggingMiddleware(logger Logger, redactor *Redactor, logBodies bool) Middleware {
+	if redactor == nil {
+		redactor = DefaultRedactor()
+	}
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+
+			var requestBody []byte
+			if logBodies {
+				var err error
+				if requestBody, err = bufferRequestBody(req); err != nil {
+					return nil, err
+				}
+			}
+
+			start := time.Now()
+			res, err := next(req)
+			duration := time.Since(start)
+
+			var responseBody []byte
+			if logBodies && err == nil && res.Body != nil {
+				if responseBody, err = bufferResponseBody(res); err != nil {
+					res = nil
+				}
+			}
+
+			args := []interface{}{
+				"method", req.Method,
+				"path", req.URL.Path,
+				"attempt", requestAttemptFromContext(ctx).attempt,
+				"duration", duration,
+			}
+			if err != nil {
+				args = append(args, "error", err.Error())
+			} else {
+				args = append(args, "status", res.StatusCode, "request_id", res.Header.Get(RequestIDHeader))
+			}
+			if logBodies {
+				args = append(args, "request_body", string(redactor.Redact(requestBody)))
+				if err == nil {
+					args = append(args, "response_body", string(redactor.Redact(responseBody)))
+				}
+			}
+
+			const msg = "EasyPost API call"
+			switch {
+			case err != nil || res.StatusCode >= 500:
+				logger.ErrorContext(ctx, msg, args...)
+			case res.StatusCode >= 400:
+				logger.WarnContext(ctx, msg, args...)
+			default:
+				logger.InfoContext(ctx, msg, args...)
+			}
+
+			return res, err
+		}
+	}
+}
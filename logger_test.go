@@ -0,0 +1,129 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type logRecord struct {
+	level string
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordingLogger is a Logger keeping the records it receives in memory.
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attrs := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	l.records = append(l.records, logRecord{level: level, msg: msg, attrs: attrs})
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("debug", msg, args)
+}
+
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("info", msg, args)
+}
+
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("warn", msg, args)
+}
+
+func (l *recordingLogger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("error", msg, args)
+}
+
+func (c *ClientTests) TestLoggerLogsAPICalls() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(
+		respondWith(422, `{"error": {"code": "ADDRESS.VERIFY.FAILURE", "message": "failed"}}`, map[string]string{RequestIDHeader: "req_1"}),
+		respondWith(200, `{"id": "adr_123", "object": "Address"}`, map[string]string{RequestIDHeader: "req_2"}),
+	)
+	logger := &recordingLogger{}
+	client.Logger = logger
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	_, err = client.GetAddress("adr_123")
+	require.NoError(err)
+
+	require.Equal(2, len(logger.records))
+	assert.Equal("warn", logger.records[0].level)
+	assert.Equal(422, logger.records[0].attrs["status"])
+	assert.Equal("req_1", logger.records[0].attrs["request_id"])
+
+	record := logger.records[1]
+	assert.Equal("info", record.level)
+	assert.Equal("GET", record.attrs["method"])
+	assert.Equal("/v2/addresses/adr_123", record.attrs["path"])
+	assert.Equal(200, record.attrs["status"])
+	assert.Equal("req_2", record.attrs["request_id"])
+	assert.Equal(1, record.attrs["attempt"])
+	assert.IsType(time.Duration(0), record.attrs["duration"])
+	assert.NotContains(record.attrs, "request_body")
+}
+
+func (c *ClientTests) TestLoggerLogsTransportErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	logger := &recordingLogger{}
+	client.Logger = logger
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	require.Equal(1, len(logger.records))
+	assert.Equal("error", logger.records[0].level)
+	assert.Contains(logger.records[0].attrs["error"], "connection refused")
+	assert.NotContains(logger.records[0].attrs, "status")
+}
+
+func (c *ClientTests) TestLoggerRedactsBodies() {
+	assert, require := c.Assert(), c.Require()
+
+	var sentBody string
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		body, _ := req.GetBody()
+		buf := make([]byte, req.ContentLength)
+		_, _ = body.Read(buf)
+		sentBody = string(buf)
+		return scriptedResponse(200, `{"id": "adr_123", "object": "Address", "street1": "417 Montgomery Street", "phone": "415-555-1212", "city": "San Francisco"}`, nil), nil
+	})
+	logger := &recordingLogger{}
+	client.Logger = logger
+	client.LogBodies = true
+
+	address, err := client.CreateAddress(&Address{Street1: "417 Montgomery Street", Phone: "415-555-1212", City: "San Francisco"}, nil)
+	require.NoError(err)
+
+	// redaction only applies to the logs
+	assert.Equal("415-555-1212", address.Phone)
+	assert.Contains(sentBody, "415-555-1212")
+
+	require.Equal(1, len(logger.records))
+	for _, key := range []string{"request_body", "response_body"} {
+		body := logger.records[0].attrs[key].(string)
+		assert.NotContains(body, "417 Montgomery Street")
+		assert.NotContains(body, "415-555-1212")
+		assert.Contains(body, RedactedValue)
+		assert.Contains(body, "San Francisco")
+	}
+}
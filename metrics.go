@@ -0,0 +1,204 @@
+package easypost
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives the metrics of the API calls made by a Client. Endpoints are templated routes such as
+// "shipments/{id}/buy", which keeps the cardinality of endpoint labels low.
+//
+// Implementations are called synchronously from the goroutine making the API call, and must be safe for concurrent
+// use. A MetricsCollector can be bridged to a metrics library such as Prometheus by updating its counters and
+// histograms from each method.
+type MetricsCollector interface {
+	// RecordRequest records an API call, including every retry attempt. The status code is the one of the last
+	// response received, or 0 if no response was received.
+	RecordRequest(method, endpoint string, statusCode int, duration time.Duration)
+	// RecordError records an API call that failed with an error of the given type: the library error type (e.g.
+	// "NotFoundError"), or ErrorTypeOther for errors outside of the library's hierarchy.
+	RecordError(method, endpoint, errorType string)
+	// RecordSpend records the cost of a label purchased with BuyShipment, from the shipment's SelectedRate.
+	RecordSpend(carrier, service, currency string, amount float64)
+}
+
+// DefaultLatencyBuckets are the upper bounds of the latency histograms kept by an InMemoryMetrics.
+var DefaultLatencyBuckets = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+type endpointKey struct {
+	method   string
+	endpoint string
+}
+
+type errorKey struct {
+	endpointKey
+	errorType string
+}
+
+type spendKey struct {
+	carrier  string
+	service  string
+	currency string
+}
+
+// InMemoryMetrics is a MetricsCollector keeping its metrics in memory, to inspect the metrics of a Client in tests.
+// An InMemoryMetrics is safe for concurrent use.
+type InMemoryMetrics struct {
+	// Buckets are the upper bounds of the latency histograms, in increasing order. If nil, DefaultLatencyBuckets will
+	// be used.
+	Buckets []time.Duration
+
+	mu        sync.Mutex
+	requests  map[endpointKey]int
+	statuses  map[endpointKey]map[int]int
+	latencies map[endpointKey][]time.Duration
+	errors    map[errorKey]int
+	spend     map[spendKey]float64
+}
+
+// NewInMemoryMetrics returns a new InMemoryMetrics.
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{}
+}
+
+// RecordRequest records an API call.
+func (m *InMemoryMetrics) RecordRequest(method, endpoint string, statusCode int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := endpointKey{method: method, endpoint: endpoint}
+	if m.requests == nil {
+		m.requests = map[endpointKey]int{}
+		m.statuses = map[endpointKey]map[int]int{}
+		m.latencies = map[endpointKey][]time.Duration{}
+	}
+	m.requests[key]++
+	if m.statuses[key] == nil {
+		m.statuses[key] = map[int]int{}
+	}
+	m.statuses[key][statusCode]++
+	m.latencies[key] = append(m.latencies[key], duration)
+}
+
+// RecordError records an API call that failed.
+func (m *InMemoryMetrics) RecordError(method, endpoint, errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.errors == nil {
+		m.errors = map[errorKey]int{}
+	}
+	m.errors[errorKey{endpointKey: endpointKey{method: method, endpoint: endpoint}, errorType: errorType}]++
+}
+
+// RecordSpend records the cost of a purchased label.
+func (m *InMemoryMetrics) RecordSpend(carrier, service, currency string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.spend == nil {
+		m.spend = map[spendKey]float64{}
+	}
+	m.spend[spendKey{carrier: carrier, service: service, currency: currency}] += amount
+}
+
+// RequestCount returns the number of API calls made to an endpoint.
+func (m *InMemoryMetrics) RequestCount(method, endpoint string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[endpointKey{method: method, endpoint: endpoint}]
+}
+
+// StatusCount returns the number of API calls made to an endpoint whose last response had the given status code.
+func (m *InMemoryMetrics) StatusCount(method, endpoint string, statusCode int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statuses[endpointKey{method: method, endpoint: endpoint}][statusCode]
+}
+
+// ErrorCount returns the number of API calls made to an endpoint that failed with an error of the given type.
+func (m *InMemoryMetrics) ErrorCount(method, endpoint, errorType string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors[errorKey{endpointKey: endpointKey{method: method, endpoint: endpoint}, errorType: errorType}]
+}
+
+// Latencies returns the durations of the API calls made to an endpoint, in the order they were recorded.
+func (m *InMemoryMetrics) Latencies(method, endpoint string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.latencies[endpointKey{method: method, endpoint: endpoint}]...)
+}
+
+// LatencyHistogram returns the number of API calls made to an endpoint whose duration falls in each bucket of
+// Buckets. The returned slice has one more element than Buckets, counting the calls slower than the last bucket.
+func (m *InMemoryMetrics) LatencyHistogram(method, endpoint string) []int {
+	buckets := m.Buckets
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+
+	histogram := make([]int, len(buckets)+1)
+	for _, latency := range m.Latencies(method, endpoint) {
+		histogram[sort.Search(len(buckets), func(i int) bool { return latency <= buckets[i] })]++
+	}
+	return histogram
+}
+
+// Spend returns the total cost of the labels purchased in the given currency.
+func (m *InMemoryMetrics) Spend(currency string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var total float64
+	for key, amount := range m.spend {
+		if key.currency == currency {
+			total += amount
+		}
+	}
+	return total
+}
+
+// SpendByService returns the total cost of the labels purchased in the given currency for a carrier's service.
+func (m *InMemoryMetrics) SpendByService(carrier, service, currency string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.spend[spendKey{carrier: carrier, service: service, currency: currency}]
+}
+
+// recordMetrics reports an API call to the client's MetricsCollector.
+func (c *Client) recordMetrics(method, endpoint string, statusCode int, duration time.Duration, err error) {
+	if c.Metrics == nil {
+		return
+	}
+	c.Metrics.RecordRequest(method, endpoint, statusCode, duration)
+	if err != nil {
+		c.Metrics.RecordError(method, endpoint, errorTypeName(err))
+	}
+}
+
+// recordSpend reports the cost of the label purchased for a shipment to the client's MetricsCollector.
+func (c *Client) recordSpend(shipment *Shipment) {
+	// synthetic shipments bought in dry-run mode cost nothing
+	if c.Metrics == nil || shipment == nil || shipment.SelectedRate == nil || Mode(shipment.Mode) == ModeDryRun {
+		return
+	}
+	rate := shipment.SelectedRate
+	amount, err := strconv.ParseFloat(rate.Rate, 64)
+	if err != nil {
+		return
+	}
+	c.Metrics.RecordSpend(rate.Carrier, rate.Service, rate.Currency, amount)
+}
@@ -0,0 +1,84 @@
+package easypost
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestMetricsRecordsRequestsAndErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(
+		respondWith(200, `{"id": "trk_123", "object": "Tracker"}`, nil),
+		respondWith(404, `{"error": {"code": "NOT_FOUND", "message": "not found"}}`, nil),
+	)
+	metrics := NewInMemoryMetrics()
+	client.Metrics = metrics
+
+	_, err := client.GetTracker("trk_123")
+	require.NoError(err)
+	_, err = client.GetTracker("trk_456")
+	require.Error(err)
+
+	assert.Equal(2, metrics.RequestCount(http.MethodGet, "trackers/{id}"))
+	assert.Equal(1, metrics.StatusCount(http.MethodGet, "trackers/{id}", 200))
+	assert.Equal(1, metrics.StatusCount(http.MethodGet, "trackers/{id}", 404))
+	assert.Equal(1, metrics.ErrorCount(http.MethodGet, "trackers/{id}", "NotFoundError"))
+	assert.Equal(2, len(metrics.Latencies(http.MethodGet, "trackers/{id}")))
+	assert.Equal(0, metrics.RequestCount(http.MethodGet, "shipments/{id}"))
+}
+
+func (c *ClientTests) TestMetricsErrorTypeIsBounded() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, nil))
+	// a middleware failing with its own error, which is not one of the library's
+	client.Middlewares = []Middleware{func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("middleware: %w", errors.New("refused by policy"))
+		}
+	}}
+	metrics := NewInMemoryMetrics()
+	client.Metrics = metrics
+
+	_, err := client.GetTracker("trk_123")
+	require.Error(err)
+	assert.Equal(1, metrics.ErrorCount(http.MethodGet, "trackers/{id}", ErrorTypeOther))
+}
+
+func (c *ClientTests) TestMetricsRecordsSpend() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(
+		respondWith(200, `{"id": "shp_1", "object": "Shipment", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "7.58", "currency": "USD"}}`, nil),
+		respondWith(200, `{"id": "shp_2", "object": "Shipment", "selected_rate": {"carrier": "USPS", "service": "Express", "rate": "30.10", "currency": "USD"}}`, nil),
+		respondWith(422, `{"error": {"code": "SHIPMENT.POSTAGE.FAILURE", "message": "failed"}}`, nil),
+	)
+	metrics := NewInMemoryMetrics()
+	client.Metrics = metrics
+
+	_, err := client.BuyShipment("shp_1", &Rate{ID: "rate_1"}, "")
+	require.NoError(err)
+	_, err = client.BuyShipment("shp_2", &Rate{ID: "rate_2"}, "")
+	require.NoError(err)
+	_, err = client.BuyShipment("shp_3", &Rate{ID: "rate_3"}, "")
+	require.Error(err)
+
+	assert.InDelta(37.68, metrics.Spend("USD"), 0.0001)
+	assert.InDelta(7.58, metrics.SpendByService("USPS", "Priority", "USD"), 0.0001)
+	assert.Equal(0.0, metrics.Spend("CAD"))
+	assert.Equal(3, metrics.RequestCount(http.MethodPost, "shipments/{id}/buy"))
+}
+
+func (c *ClientTests) TestInMemoryMetricsLatencyHistogram() {
+	assert := c.Assert()
+
+	metrics := &InMemoryMetrics{Buckets: []time.Duration{100 * time.Millisecond, time.Second}}
+	for _, latency := range []time.Duration{10 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		metrics.RecordRequest(http.MethodGet, "addresses/{id}", 200, latency)
+	}
+
+	assert.Equal([]int{2, 1, 1}, metrics.LatencyHistogram(http.MethodGet, "addresses/{id}"))
+}
@@ -0,0 +1,88 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Handler sends a single attempt of an API request and returns the API's response.
+//
+// The request's context carries the deadline of the attempt. A Handler must return either a non-nil response or a
+// non-nil error.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to act on the requests sent by a Client and the responses it receives.
+//
+// A Middleware can modify the request (e.g. add headers) before calling next, short-circuit the request by returning a
+// response or error without calling next (e.g. to serve a cached response or enforce a policy), or replace the
+// response returned by next. The request body, if any, can be re-read through req.GetBody.
+//
+//	func tenantMiddleware(tenantID string) easypost.Middleware {
+//		return func(next easypost.Handler) easypost.Handler {
+//			return func(req *http.Request) (*http.Response, error) {
+//				req.Header.Set("X-Tenant-ID", tenantID)
+//				return next(req)
+//			}
+//		}
+//	}
+type Middleware func(next Handler) Handler
+
+// Use appends middlewares to the Client's Middlewares. Middlewares wrap each other in the order they were added, the
+// first one being the outermost.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.Middlewares = append(c.Middlewares, middlewares...)
+}
+
+// handler builds the chain of middlewares a request attempt goes through.
+//
+// The chain is, from outermost to innermost: the Client's Middlewares, LoggingMiddleware (if the Client has a
+// Logger), HooksMiddleware, DryRunMiddleware (if the Client has a DryRun), MockRequestsMiddleware (if the Client has
+// MockRequests) and finally the HTTP client.
+func (c *Client) handler() Handler {
+	handler := Handler(func(req *http.Request) (*http.Response, error) {
+		res, err := c.client().Do(req)
+		return res, newTransportError(err)
+	})
+
+	if len(c.MockRequests) > 0 {
+		// If there are mock requests set, this client will ONLY make mock requests
+		handler = c.mocks().middleware(c.MockRequests)(handler)
+	}
+	if c.DryRun != nil {
+		handler = DryRunMiddleware(c.DryRun)(handler)
+	}
+	handler = HooksMiddleware(&c.Hooks)(handler)
+	if c.Logger != nil {
+		handler = LoggingMiddleware(c.Logger, c.LogRedactor, c.LogBodies)(handler)
+	}
+
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		handler = c.Middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// requestAttempt identifies an attempt of an API call, shared with the middlewares through the request context.
+type requestAttempt struct {
+	id      uuid.UUID
+	attempt int
+}
+
+type requestAttemptContextKey struct{}
+
+// contextWithRequestAttempt returns a copy of ctx carrying the ID and attempt number of an API call.
+func contextWithRequestAttempt(ctx context.Context, id uuid.UUID, attempt int) context.Context {
+	return context.WithValue(ctx, requestAttemptContextKey{}, requestAttempt{id: id, attempt: attempt})
+}
+
+// requestAttemptFromContext returns the ID and attempt number of the API call carried by ctx. A new ID is returned if
+// ctx does not carry one, i.e. when a middleware is used outside of a Client.
+func requestAttemptFromContext(ctx context.Context) requestAttempt {
+	if attempt, ok := ctx.Value(requestAttemptContextKey{}).(requestAttempt); ok {
+		return attempt
+	}
+	return requestAttempt{id: uuid.New(), attempt: 1}
+}
@@ -0,0 +1,121 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+func (c *ClientTests) TestMiddlewareModifiesRequest() {
+	assert, require := c.Assert(), c.Require()
+
+	var tenant string
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		tenant = req.Header.Get("X-Tenant-ID")
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, nil), nil
+	})
+	client.Use(func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Tenant-ID", "tenant_123")
+			return next(req)
+		}
+	})
+
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+
+	assert.Equal("tenant_123", tenant)
+}
+
+func (c *ClientTests) TestMiddlewareShortCircuits() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(500, `{}`, nil))
+	client.Use(func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				cached := MockRequestResponseInfo{StatusCode: 200, Body: `{"id": "shp_123", "object": "Shipment"}`}
+				return cached.AsResponse(), nil
+			}
+			return next(req)
+		}
+	})
+
+	shipment, err := client.GetShipment("shp_123")
+	require.NoError(err)
+
+	assert.Equal("shp_123", shipment.ID)
+	assert.Equal(0, *calls)
+}
+
+func (c *ClientTests) TestMiddlewareOrder() {
+	assert, require := c.Assert(), c.Require()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" before")
+				res, err := next(req)
+				order = append(order, name+" after")
+				return res, err
+			}
+		}
+	}
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		order = append(order, "transport")
+		return scriptedResponse(200, `{}`, nil), nil
+	})
+	client.Use(trace("first"), trace("second"))
+	client.Hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event RequestHookEvent) error {
+			order = append(order, "request hook")
+			return nil
+		},
+	})
+	client.Hooks.AddResponseEventSubscriber(ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event ResponseHookEvent) error {
+			order = append(order, "response hook")
+			return nil
+		},
+	})
+
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+
+	// user middlewares wrap the built-in hooks middleware, which wraps the transport
+	assert.Equal([]string{
+		"first before", "second before", "request hook", "transport", "response hook", "second after", "first after",
+	}, order)
+}
+
+func (c *ClientTests) TestMiddlewareWrapsMockRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	var sawRequest bool
+	client := c.MockClient([]MockRequest{
+		{
+			MatchRule: MockRequestMatchRule{Method: http.MethodGet, UrlRegexPattern: "v2/shipments/shp_123$"},
+			ResponseInfo: MockRequestResponseInfo{
+				StatusCode: 200,
+				Body:       `{"id": "shp_123", "object": "Shipment"}`,
+			},
+		},
+	})
+	client.Use(func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			sawRequest = true
+			return next(req)
+		}
+	})
+
+	shipment, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal("shp_123", shipment.ID)
+	assert.True(sawRequest)
+
+	_, err = client.GetShipment("shp_456")
+	require.Error(err)
+	assert.True(strings.Contains(err.Error(), "no matching mock request found"))
+}
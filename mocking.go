@@ -1,50 +1,305 @@
 package easypost
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 type MockRequestMatchRule struct {
 	Method          string
 	UrlRegexPattern string
+	// Query lists query parameters the request URL must have, with the given values.
+	Query map[string]string
+	// Headers lists headers the request must have, with the given values.
+	Headers map[string]string
+	// BodyPredicate, if set, must return true for the JSON object the request body decodes to. It is given nil when
+	// the request has no body, and requests whose body is not a JSON object do not match.
+	BodyPredicate func(body map[string]interface{}) bool
 }
 
 type MockRequestResponseInfo struct {
 	StatusCode int
 	Body       string
+	// Headers lists the headers of the response, e.g. a Retry-After header sent with a 429 status code.
+	Headers map[string]string
+	// Delay holds the response back for the given duration, as a slow API would. The request fails with a timeout if
+	// the Client's Timeout, or its context, expires first.
+	Delay time.Duration
+	// Error, if set, fails the request at the transport level with the given error instead of returning a response, as
+	// a network failure would (e.g. syscall.ECONNRESET).
+	Error error
+	// BodyError, if set, is returned when reading the response body past Body, as a connection dropped while the body
+	// is being received would (e.g. io.ErrUnexpectedEOF). Combined with a partial Body, it simulates a truncated
+	// response.
+	BodyError error
 }
 
 func (r *MockRequestResponseInfo) MockBody() io.ReadCloser {
+	if r.BodyError != nil {
+		return io.NopCloser(io.MultiReader(strings.NewReader(r.Body), &errorReader{err: r.BodyError}))
+	}
 	return io.NopCloser(strings.NewReader(r.Body))
 }
 
+// errorReader is an io.Reader that always fails with the same error.
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
 type MockRequest struct {
 	MatchRule    MockRequestMatchRule
 	ResponseInfo MockRequestResponseInfo
+	// Responses, if set, are returned in order to the requests matched by the mock request, in place of
+	// ResponseInfo. The last one is returned again once they have all been used.
+	Responses []MockRequestResponseInfo
+	// Times is the number of requests the mock request answers. Once they are answered, requests go on to the next
+	// mock request that matches them, so that, for instance, a one-shot mock request (Times: 1) can be followed by
+	// another one for the same URL. If zero, the mock request answers every request that matches it.
+	Times int
+	// Optional excludes the mock request from the checks of VerifyMockRequests.
+	Optional bool
 }
 
 func (r *MockRequestResponseInfo) AsResponse() *http.Response {
+	header := http.Header{}
+	for key, value := range r.Headers {
+		header.Set(key, value)
+	}
 	return &http.Response{
 		Status:        http.StatusText(r.StatusCode),
 		StatusCode:    r.StatusCode,
 		Body:          r.MockBody(),
 		ContentLength: int64(len(r.Body)),
-		Header:        nil,
+		Header:        header,
 		Request:       nil,
 	}
 }
 
-func (c *Client) findMatchingMockRequest(req *http.Request) *http.Response {
-	for _, mockReq := range c.MockRequests {
-		url := req.URL.String()
-		methodMatch := mockReq.MatchRule.Method == "" || mockReq.MatchRule.Method == req.Method
-		urlMatch, _ := regexp.MatchString(mockReq.MatchRule.UrlRegexPattern, url)
-		if methodMatch && urlMatch {
-			return mockReq.ResponseInfo.AsResponse()
+// respond waits for the Delay of the response, then returns it or fails with its Error. Errors are returned as the
+// HTTP client's would be, so that they go through the same error handling.
+func (r *MockRequestResponseInfo) respond(req *http.Request) (*http.Response, error) {
+	if r.Delay > 0 {
+		timer := time.NewTimer(r.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, newTransportError(req.Context().Err())
+		}
+	}
+	if r.Error != nil {
+		return nil, newTransportError(r.Error)
+	}
+	return r.AsResponse(), nil
+}
+
+// MockCall is a request received by a client with mock requests.
+type MockCall struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the full URL of the request.
+	URL string
+	// Header holds the headers of the request.
+	Header http.Header
+	// Body is the body of the request, if any.
+	Body []byte
+	// MockRequest is the index of the mock request that answered the request, or -1 if none matched it.
+	MockRequest int
+}
+
+// MockTestingT is the part of *testing.T used by VerifyMockRequests.
+type MockTestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// errNoMatchingMockRequest is returned when a client with mock requests receives a request that none of them match.
+var errNoMatchingMockRequest = errors.New("no matching mock request found")
+
+// mockState keeps track of the requests answered by a list of mock requests, across the attempts of every API call.
+type mockState struct {
+	mu    sync.Mutex
+	hits  []int
+	calls []MockCall
+}
+
+// MockRequestsMiddleware returns a Middleware that answers requests with the first of the given mock requests that
+// matches them, without calling the next Handler. Requests that do not match any mock request fail with an error.
+func MockRequestsMiddleware(mockRequests []MockRequest) Middleware {
+	return (&mockState{}).middleware(mockRequests)
+}
+
+func (s *mockState) middleware(mockRequests []MockRequest) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			info, err := s.answer(mockRequests, req)
+			if err != nil {
+				return nil, err
+			}
+			if info == nil {
+				return nil, fmt.Errorf("%w: %s %s", errNoMatchingMockRequest, req.Method, req.URL.String())
+			}
+			return info.respond(req)
+		}
+	}
+}
+
+// answer records the request and returns the response of the first mock request that matches it and has not
+// answered all its requests yet, or nil if there is none.
+func (s *mockState) answer(mockRequests []MockRequest, req *http.Request) (*MockRequestResponseInfo, error) {
+	body, err := bufferRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.hits) < len(mockRequests) {
+		s.hits = append(s.hits, make([]int, len(mockRequests)-len(s.hits))...)
+	}
+
+	call := MockCall{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Header:      req.Header.Clone(),
+		Body:        body,
+		MockRequest: -1,
+	}
+	defer func() { s.calls = append(s.calls, call) }()
+
+	i := findMatchingMockRequest(mockRequests, s.hits, req, body)
+	if i < 0 {
+		return nil, nil
+	}
+	call.MockRequest = i
+	mockReq := mockRequests[i]
+	info := mockReq.ResponseInfo
+	if len(mockReq.Responses) > 0 {
+		n := s.hits[i]
+		if n >= len(mockReq.Responses) {
+			n = len(mockReq.Responses) - 1
 		}
+		info = mockReq.Responses[n]
 	}
-	return nil
+	s.hits[i]++
+	return &info, nil
+}
+
+// findMatchingMockRequest returns the index of the first mock request that matches the request and has answered
+// fewer requests than its Times, or -1 if there is none.
+func findMatchingMockRequest(mockRequests []MockRequest, hits []int, req *http.Request, body []byte) int {
+	for i, mockReq := range mockRequests {
+		if mockReq.Times > 0 && hits[i] >= mockReq.Times {
+			continue
+		}
+		if mockReq.MatchRule.matches(req, body) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matches reports whether the request, with the given body, satisfies every condition of the rule.
+func (rule *MockRequestMatchRule) matches(req *http.Request, body []byte) bool {
+	if rule.Method != "" && rule.Method != req.Method {
+		return false
+	}
+	if urlMatch, _ := regexp.MatchString(rule.UrlRegexPattern, req.URL.String()); !urlMatch {
+		return false
+	}
+
+	query := req.URL.Query()
+	for key, value := range rule.Query {
+		if values, ok := query[key]; !ok || values[0] != value {
+			return false
+		}
+	}
+	for key, value := range rule.Headers {
+		if req.Header.Get(key) != value {
+			return false
+		}
+	}
+
+	if rule.BodyPredicate != nil {
+		var params map[string]interface{}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &params); err != nil || params == nil {
+				return false
+			}
+		}
+		if !rule.BodyPredicate(params) {
+			return false
+		}
+	}
+	return true
+}
+
+// verify reports the mock requests that did not answer the requests they were expected to.
+func (s *mockState) verify(t MockTestingT, mockRequests []MockRequest) bool {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ok := true
+	for i, mockReq := range mockRequests {
+		hits := 0
+		if i < len(s.hits) {
+			hits = s.hits[i]
+		}
+		rule := mockReq.MatchRule
+		switch {
+		case mockReq.Optional:
+		case hits == 0:
+			t.Errorf("mock request %d (%s %s) was never hit", i, rule.Method, rule.UrlRegexPattern)
+			ok = false
+		case mockReq.Times > 0 && hits < mockReq.Times:
+			t.Errorf("mock request %d (%s %s) was hit %d times, expected %d", i, rule.Method, rule.UrlRegexPattern, hits, mockReq.Times)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// mocks returns the state of the Client's mock requests, which outlives the middleware chain built for each attempt.
+func (c *Client) mocks() *mockState {
+	return c.state().mockState
+}
+
+// MockCalls returns the requests received by the Client's mock requests so far, in order, including those that no
+// mock request matched.
+func (c *Client) MockCalls() []MockCall {
+	state := c.mocks()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return append([]MockCall(nil), state.calls...)
+}
+
+// ResetMockRequests forgets the requests received by the Client's mock requests, so that one-shot and ordered mock
+// requests start over.
+func (c *Client) ResetMockRequests() {
+	state := c.mocks()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.hits, state.calls = nil, nil
+}
+
+// VerifyMockRequests fails the test if one of the Client's mock requests was never hit, or answered fewer requests
+// than its Times. Mock requests marked as Optional are not checked. It returns whether every check passed.
+//
+//	client := &easypost.Client{APIKey: "test", MockRequests: mockRequests}
+//	defer client.VerifyMockRequests(t)
+func (c *Client) VerifyMockRequests(t MockTestingT) bool {
+	t.Helper()
+	return c.mocks().verify(t, c.MockRequests)
 }
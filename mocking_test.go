@@ -0,0 +1,200 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+)
+
+// fakeMockTestingT records the errors reported by VerifyMockRequests.
+type fakeMockTestingT struct {
+	errors []string
+}
+
+func (t *fakeMockTestingT) Helper() {}
+
+func (t *fakeMockTestingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (c *ClientTests) TestMockRequestsSequencing() {
+	assert, require := c.Assert(), c.Require()
+
+	client := c.MockClient([]MockRequest{
+		{
+			MatchRule:    MockRequestMatchRule{Method: "POST", UrlRegexPattern: "v2/shipments$"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 201, Body: `{"id": "shp_1", "status": "unknown"}`},
+			Times:        1,
+		},
+		{
+			MatchRule: MockRequestMatchRule{UrlRegexPattern: "v2/shipments"},
+			Responses: []MockRequestResponseInfo{
+				{StatusCode: 200, Body: `{"id": "shp_1", "status": "pre_transit"}`},
+				{StatusCode: 200, Body: `{"id": "shp_1", "status": "delivered"}`},
+			},
+		},
+	})
+
+	shipment, err := client.CreateShipment(&Shipment{})
+	require.NoError(err)
+	assert.Equal("unknown", shipment.Status)
+
+	var statuses []string
+	for i := 0; i < 3; i++ {
+		shipment, err = client.GetShipment("shp_1")
+		require.NoError(err)
+		statuses = append(statuses, shipment.Status)
+	}
+	// the last response is repeated once they have all been used
+	assert.Equal([]string{"pre_transit", "delivered", "delivered"}, statuses)
+
+	// the one-shot mock request is used up, so a second create falls through to the next mock request
+	shipment, err = client.CreateShipment(&Shipment{})
+	require.NoError(err)
+	assert.Equal("delivered", shipment.Status)
+
+	client.ResetMockRequests()
+	shipment, err = client.CreateShipment(&Shipment{})
+	require.NoError(err)
+	assert.Equal("unknown", shipment.Status)
+}
+
+func (c *ClientTests) TestMockRequestsMatching() {
+	assert, require := c.Assert(), c.Require()
+
+	client := c.MockClient([]MockRequest{
+		{
+			MatchRule: MockRequestMatchRule{
+				Method:          "POST",
+				UrlRegexPattern: "v2/shipments$",
+				BodyPredicate: func(body map[string]interface{}) bool {
+					shipment, _ := body["shipment"].(map[string]interface{})
+					return shipment["reference"] == "vip"
+				},
+			},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 201, Body: `{"id": "shp_vip"}`},
+		},
+		{
+			MatchRule: MockRequestMatchRule{
+				Method:          "GET",
+				UrlRegexPattern: "v2/shipments",
+				Query:           map[string]string{"page_size": "5"},
+				Headers:         map[string]string{"X-Team": "ops"},
+			},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 200, Body: `{"shipments": [{"id": "shp_ops"}]}`},
+		},
+		{
+			MatchRule:    MockRequestMatchRule{Method: "GET", UrlRegexPattern: "v2/addresses"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 200, Body: `{"addresses": []}`},
+			Optional:     true,
+		},
+		{
+			MatchRule:    MockRequestMatchRule{Method: "DELETE", UrlRegexPattern: "v2/webhooks"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 204},
+			Times:        2,
+		},
+	})
+
+	shipment, err := client.CreateShipment(&Shipment{Reference: "vip"})
+	require.NoError(err)
+	assert.Equal("shp_vip", shipment.ID)
+
+	_, err = client.CreateShipment(&Shipment{Reference: "regular"})
+	assert.True(errors.Is(err, errNoMatchingMockRequest))
+
+	ctx := ContextWithRequestOptions(context.Background(), WithHeader("X-Team", "ops"))
+	shipments, err := client.ListShipmentsWithContext(ctx, &ListShipmentsOptions{PageSize: 5})
+	require.NoError(err)
+	assert.Equal("shp_ops", shipments.Shipments[0].ID)
+
+	_, err = client.ListShipments(&ListShipmentsOptions{PageSize: 5})
+	assert.True(errors.Is(err, errNoMatchingMockRequest))
+
+	require.NoError(client.DeleteWebhook("hook_1"))
+
+	calls := client.MockCalls()
+	require.Len(calls, 5)
+	assert.Equal(0, calls[0].MockRequest)
+	assert.Contains(string(calls[0].Body), `"reference":"vip"`)
+	assert.Equal(-1, calls[1].MockRequest)
+	assert.Equal("ops", calls[2].Header.Get("X-Team"))
+	assert.Equal(-1, calls[3].MockRequest)
+	assert.Equal("DELETE", calls[4].Method)
+
+	t := &fakeMockTestingT{}
+	assert.False(client.VerifyMockRequests(t))
+	assert.Equal([]string{"mock request 3 (DELETE v2/webhooks) was hit 1 times, expected 2"}, t.errors)
+
+	require.NoError(client.DeleteWebhook("hook_2"))
+	t = &fakeMockTestingT{}
+	assert.True(client.VerifyMockRequests(t))
+	assert.Empty(t.errors)
+}
+
+func (c *ClientTests) TestMockRequestsFaultInjection() {
+	assert, require := c.Assert(), c.Require()
+
+	client := c.MockClient([]MockRequest{
+		{
+			MatchRule: MockRequestMatchRule{UrlRegexPattern: "v2/shipments/shp_limited"},
+			Responses: []MockRequestResponseInfo{
+				{StatusCode: 429, Body: `{"error": {"code": "RATE_LIMITED", "message": "slow down"}}`, Headers: map[string]string{"Retry-After": "120"}},
+				{StatusCode: 200, Body: `{"id": "shp_limited"}`},
+			},
+		},
+		{
+			MatchRule:    MockRequestMatchRule{UrlRegexPattern: "v2/shipments/shp_slow"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 200, Body: `{"id": "shp_slow"}`, Delay: time.Minute},
+		},
+		{
+			MatchRule:    MockRequestMatchRule{UrlRegexPattern: "v2/shipments/shp_reset"},
+			ResponseInfo: MockRequestResponseInfo{Error: syscall.ECONNRESET},
+		},
+		{
+			MatchRule:    MockRequestMatchRule{UrlRegexPattern: "v2/shipments/shp_truncated"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 200, Body: `{"id": "shp_trun`, BodyError: io.ErrUnexpectedEOF},
+		},
+		{
+			MatchRule:    MockRequestMatchRule{UrlRegexPattern: "v2/shipments/shp_corrupt"},
+			ResponseInfo: MockRequestResponseInfo{StatusCode: 502, Body: `<html>Bad Gateway</html>`},
+		},
+	})
+
+	_, err := client.GetShipment("shp_limited")
+	var rateLimitError *RateLimitError
+	require.True(errors.As(err, &rateLimitError))
+	assert.Equal("120", rateLimitError.Metadata.Headers.Get("Retry-After"))
+
+	// the next response of the mock request answers the retry
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, IgnoreRetryAfter: true}
+	client.ResetMockRequests()
+	shipment, err := client.GetShipment("shp_limited")
+	require.NoError(err)
+	assert.Equal("shp_limited", shipment.ID)
+	assert.Len(client.MockCalls(), 2)
+	client.RetryPolicy = nil
+
+	client.Timeout = 20
+	start := time.Now()
+	_, err = client.GetShipment("shp_slow")
+	var timeoutError *TimeoutError
+	assert.True(errors.As(err, &timeoutError))
+	assert.Less(time.Since(start), 10*time.Second)
+
+	_, err = client.GetShipment("shp_reset")
+	var connectionError *ConnectionError
+	assert.True(errors.As(err, &connectionError))
+	assert.True(errors.Is(err, syscall.ECONNRESET))
+
+	_, err = client.GetShipment("shp_truncated")
+	assert.True(errors.As(err, &connectionError))
+	assert.True(errors.Is(err, io.ErrUnexpectedEOF))
+
+	_, err = client.GetShipment("shp_corrupt")
+	var gatewayTimeoutError *GatewayTimeoutError
+	require.True(errors.As(err, &gatewayTimeoutError))
+	assert.Equal(502, gatewayTimeoutError.StatusCode)
+}
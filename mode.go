@@ -0,0 +1,175 @@
+package easypost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Mode is the mode an API key operates in. Objects created in test mode are free and never shipped, while purchases
+// made in production mode are billed.
+type Mode string
+
+const (
+	// ModeUnknown is the mode of an API key that could not be identified.
+	ModeUnknown Mode = ""
+	// ModeTest is the mode of test API keys.
+	ModeTest Mode = "test"
+	// ModeProduction is the mode of production API keys.
+	ModeProduction Mode = "production"
+)
+
+const (
+	testAPIKeyPrefix       = "EZTK"
+	productionAPIKeyPrefix = "EZAK"
+)
+
+// purchaseRoutes are the templated paths (see templatePath) of the POST endpoints that spend money, refused by
+// Client.SafeMode in production mode.
+var purchaseRoutes = []string{
+	"bank_accounts/{id}/charges",
+	"batches/{id}/buy",
+	"credit_cards/{id}/charges",
+	"insurances",
+	"orders/{id}/buy",
+	"pickups/{id}/buy",
+	"shipments/luma",
+	"shipments/{id}/buy",
+	"shipments/{id}/insure",
+	"shipments/{id}/luma",
+}
+
+// modeFromAPIKey returns the mode of an API key from its prefix.
+func modeFromAPIKey(apiKey string) Mode {
+	switch {
+	case strings.HasPrefix(apiKey, testAPIKeyPrefix):
+		return ModeTest
+	case strings.HasPrefix(apiKey, productionAPIKeyPrefix):
+		return ModeProduction
+	default:
+		return ModeUnknown
+	}
+}
+
+// Mode returns the mode of the Client's API key, identified from its prefix or, failing that, by a previous call to
+// DetectMode.
+func (c *Client) Mode() Mode {
+	if mode := modeFromAPIKey(c.APIKey); mode != ModeUnknown {
+		return mode
+	}
+
+	state := c.state()
+	state.modeMu.Lock()
+	defer state.modeMu.Unlock()
+	if state.detectedModeKey != c.APIKey {
+		return ModeUnknown
+	}
+	return state.detectedMode
+}
+
+// DetectMode returns the mode of the Client's API key. When it cannot be identified from its prefix, the API keys of
+// the current user are retrieved with RetrieveMe to find it, and the result is remembered by the Client.
+func (c *Client) DetectMode() (Mode, error) {
+	return c.DetectModeWithContext(context.Background())
+}
+
+// DetectModeWithContext performs the same operation as DetectMode, but allows
+// specifying a context that can interrupt the request.
+func (c *Client) DetectModeWithContext(ctx context.Context) (Mode, error) {
+	if mode := c.Mode(); mode != ModeUnknown {
+		return mode, nil
+	}
+
+	apiKey := c.APIKey
+	user, err := c.RetrieveMeWithContext(ctx)
+	if err != nil {
+		return ModeUnknown, err
+	}
+
+	mode := findAPIKeyMode(user, apiKey)
+	state := c.state()
+	state.modeMu.Lock()
+	state.detectedMode, state.detectedModeKey = mode, apiKey
+	state.modeMu.Unlock()
+	return mode, nil
+}
+
+// findAPIKeyMode returns the mode of the given API key among the keys of a user and its children.
+func findAPIKeyMode(user *User, apiKey string) Mode {
+	if user == nil {
+		return ModeUnknown
+	}
+	for _, key := range user.APIKeys {
+		if key != nil && key.Key == apiKey {
+			return Mode(key.Mode)
+		}
+	}
+	for _, child := range user.Children {
+		if mode := findAPIKeyMode(child, apiKey); mode != ModeUnknown {
+			return mode
+		}
+	}
+	return ModeUnknown
+}
+
+// AllowProductionPurchase allows a purchase API call to go through while Client.SafeMode is enabled in production
+// mode.
+func AllowProductionPurchase() RequestOption {
+	return func(o *requestOptions) {
+		o.allowProductionPurchase = true
+	}
+}
+
+// checkSafeMode returns a ProductionModeError if SafeMode refuses the API call.
+func (c *Client) checkSafeMode(method, route string, params interface{}, options *requestOptions) error {
+	if !c.SafeMode || c.DryRun != nil || options.allowProductionPurchase || method != http.MethodPost || c.Mode() == ModeTest {
+		return nil
+	}
+	if containsString(purchaseRoutes, route) {
+		return newProductionModeError(method + " " + route)
+	}
+	if route == "shipments" && isOneCallBuy(params) {
+		return newProductionModeError(method + " " + route + " (one-call buy)")
+	}
+	return nil
+}
+
+// isOneCallBuy returns true if the params of a POST to shipments set a service, in which case the API buys the
+// shipment as it creates it.
+func isOneCallBuy(params interface{}) bool {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return false
+	}
+	var request struct {
+		Shipment struct {
+			Service string `json:"service"`
+		} `json:"shipment"`
+	}
+	return json.Unmarshal(body, &request) == nil && request.Shipment.Service != ""
+}
+
+// warnOnModeMismatch logs a warning if the object returned by the API is in a different mode than the Client.
+func (c *Client) warnOnModeMismatch(ctx context.Context, req *http.Request, body []byte) {
+	if c.Logger == nil {
+		return
+	}
+	mode := c.Mode()
+	if mode == ModeUnknown {
+		return
+	}
+
+	var object struct {
+		Mode Mode `json:"mode"`
+	}
+	if err := json.Unmarshal(body, &object); err != nil || object.Mode == ModeUnknown || object.Mode == ModeDryRun || object.Mode == mode {
+		return
+	}
+	c.Logger.WarnContext(ctx, "EasyPost object mode does not match the client's mode",
+		"method", req.Method,
+		"path", req.URL.Path,
+		"client_mode", string(mode),
+		"object_mode", string(object.Mode),
+	)
+}
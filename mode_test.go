@@ -0,0 +1,110 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+)
+
+func (c *ClientTests) TestModeFromAPIKeyPrefix() {
+	assert := c.Assert()
+
+	assert.Equal(ModeTest, New("EZTK123").Mode())
+	assert.Equal(ModeProduction, New("EZAK123").Mode())
+	assert.Equal(ModeUnknown, New("cannot_be_blank").Mode())
+}
+
+func (c *ClientTests) TestDetectModeRetrievesMe() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{
+		"id": "user_123",
+		"object": "User",
+		"api_keys": [{"object": "ApiKey", "key": "another_key", "mode": "test"}],
+		"children": [{"id": "user_456", "api_keys": [{"object": "ApiKey", "key": "cannot_be_blank", "mode": "production"}]}]
+	}`, nil))
+
+	mode, err := client.DetectMode()
+	require.NoError(err)
+	assert.Equal(ModeProduction, mode)
+	assert.Equal(ModeProduction, client.Mode())
+
+	mode, err = client.DetectMode()
+	require.NoError(err)
+	assert.Equal(ModeProduction, mode)
+	assert.Equal(1, *calls)
+
+	// the detected mode is forgotten when the API key changes
+	client.APIKey = "EZTK123"
+	assert.Equal(ModeTest, client.Mode())
+	client.APIKey = "another_unknown_key"
+	assert.Equal(ModeUnknown, client.Mode())
+}
+
+func (c *ClientTests) TestSafeModeRefusesProductionPurchases() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{"id": "shp_123", "object": "Shipment", "mode": "production"}`, nil))
+	client.APIKey = "EZAK123"
+	client.SafeMode = true
+
+	_, err := client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	var productionModeError *ProductionModeError
+	require.True(errors.As(err, &productionModeError))
+	assert.Equal(PurchaseRefusedInProductionMode+"POST shipments/{id}/buy", err.Error())
+
+	_, err = client.CreateAndBuyLumaShipment(&LumaRequest{})
+	assert.True(errors.As(err, &productionModeError))
+
+	// creating a shipment with a service buys it in the same call
+	_, err = client.CreateShipment(&Shipment{Carrier: "USPS", Service: "Priority"})
+	require.True(errors.As(err, &productionModeError))
+	assert.Equal(PurchaseRefusedInProductionMode+"POST shipments (one-call buy)", err.Error())
+	assert.Equal(0, *calls)
+
+	_, err = client.CreateShipment(&Shipment{})
+	require.NoError(err)
+	assert.Equal(1, *calls)
+
+	// non-purchase calls are not affected
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal(2, *calls)
+
+	ctx := ContextWithRequestOptions(context.Background(), AllowProductionPurchase())
+	_, err = client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	assert.Equal(3, *calls)
+
+	client.APIKey = "EZTK123"
+	_, err = client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	assert.Equal(4, *calls)
+}
+
+func (c *ClientTests) TestModeMismatchWarning() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(
+		respondWith(200, `{"id": "shp_123", "object": "Shipment", "mode": "test"}`, nil),
+		respondWith(200, `{"id": "shp_123", "object": "Shipment", "mode": "production"}`, nil),
+	)
+	client.APIKey = "EZTK123"
+	logger := &recordingLogger{}
+	client.Logger = logger
+
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+
+	var warnings []logRecord
+	for _, record := range logger.records {
+		if record.level == "warn" {
+			warnings = append(warnings, record)
+		}
+	}
+	require.Len(warnings, 1)
+	assert.Equal("test", warnings[0].attrs["client_mode"])
+	assert.Equal("production", warnings[0].attrs["object_mode"])
+	assert.Equal("/v2/shipments/shp_123", warnings[0].attrs["path"])
+}
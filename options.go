@@ -0,0 +1,202 @@
+package easypost
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	// EnvAPIKey is the environment variable holding the API key. It is required.
+	EnvAPIKey = "EASYPOST_API_KEY"
+	// EnvBaseURL is the environment variable holding the base URL of the API.
+	EnvBaseURL = "EASYPOST_BASE_URL"
+	// EnvTimeout is the environment variable holding the timeout of API calls, either as a duration (e.g. "30s") or as
+	// a number of milliseconds.
+	EnvTimeout = "EASYPOST_TIMEOUT"
+	// EnvProxyURL is the environment variable holding the URL of the proxy used to reach the API. If unset, the
+	// standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	EnvProxyURL = "EASYPOST_PROXY_URL"
+)
+
+// Option configures a Client created with NewWithOptions or NewFromEnv.
+type Option func(c *Client) error
+
+// NewWithOptions returns a new Client with the given API key, configured with the given options.
+//
+//	client, err := easypost.NewWithOptions(apiKey,
+//		easypost.WithDefaultTimeout(30*time.Second),
+//		easypost.WithRetryPolicy(easypost.NewRetryPolicy(3)),
+//		easypost.WithLogger(slog.Default()),
+//	)
+func NewWithOptions(apiKey string, options ...Option) (*Client, error) {
+	if apiKey == "" {
+		return nil, newMissingPropertyError("APIKey")
+	}
+
+	client := New(apiKey)
+	for _, option := range options {
+		if err := option(client); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// NewFromEnv returns a new Client configured from the EnvAPIKey, EnvBaseURL, EnvTimeout and EnvProxyURL environment
+// variables, then with the given options. A MissingPropertyError is returned if EnvAPIKey is not set, and an
+// InvalidObjectError if one of the other variables is set to an invalid value.
+func NewFromEnv(options ...Option) (*Client, error) {
+	apiKey := os.Getenv(EnvAPIKey)
+	if apiKey == "" {
+		return nil, newMissingPropertyError(EnvAPIKey)
+	}
+
+	var envOptions []Option
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		envOptions = append(envOptions, WithBaseURL(baseURL))
+	}
+	if timeout := os.Getenv(EnvTimeout); timeout != "" {
+		duration, ok := parseEnvDuration(timeout)
+		if !ok {
+			return nil, newInvalidObjectError(InvalidParameter + EnvTimeout + " must be a positive duration (e.g. \"30s\") or number of milliseconds")
+		}
+		envOptions = append(envOptions, WithDefaultTimeout(duration))
+	}
+	if proxyURL := os.Getenv(EnvProxyURL); proxyURL != "" {
+		envOptions = append(envOptions, WithProxy(proxyURL))
+	}
+
+	return NewWithOptions(apiKey, append(envOptions, options...)...)
+}
+
+// parseEnvDuration parses a positive duration given either in time.ParseDuration format or as a number of milliseconds.
+func parseEnvDuration(value string) (time.Duration, bool) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		milliseconds, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false
+		}
+		duration = time.Duration(milliseconds) * time.Millisecond
+	}
+	return duration, duration > 0
+}
+
+// parseAbsoluteURL parses a URL, returning an InvalidObjectError naming the parameter if it is not an absolute URL.
+func parseAbsoluteURL(parameter, value string) (*url.URL, error) {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, newInvalidObjectError(InvalidParameter + parameter + " must be an absolute URL")
+	}
+	return parsed, nil
+}
+
+// WithBaseURL sets the base URL of the API (e.g. "https://api.easypost.com/v2/").
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		parsed, err := parseAbsoluteURL("base URL", baseURL)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(parsed.Path, "/") {
+			parsed.Path += "/"
+		}
+		c.BaseURL = parsed
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make API requests. It is never modified by this library.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		c.Client = httpClient
+		return nil
+	}
+}
+
+// WithDefaultTimeout sets the time limit of each attempt of the API calls made by the Client. The Client's Timeout is
+// in milliseconds, so the timeout is rounded up to the next millisecond.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return newInvalidObjectError(InvalidParameter + "timeout must be positive")
+		}
+		// a sub-millisecond timeout must not truncate to 0, which means the default timeout
+		c.Timeout = int((timeout + time.Millisecond - 1) / time.Millisecond)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with API requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithProxy sets the URL of the proxy used to reach the API. It only applies when no HTTP client is set with
+// WithHTTPClient.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) error {
+		parsed, err := parseAbsoluteURL("proxy URL", proxyURL)
+		if err != nil {
+			return err
+		}
+		c.Proxy = parsed
+		return nil
+	}
+}
+
+// WithRequestHook adds a RequestHookEventSubscriber to the Client's Hooks.
+func WithRequestHook(subscriber RequestHookEventSubscriber) Option {
+	return func(c *Client) error {
+		c.Hooks.AddRequestEventSubscriber(subscriber)
+		return nil
+	}
+}
+
+// WithResponseHook adds a ResponseHookEventSubscriber to the Client's Hooks.
+func WithResponseHook(subscriber ResponseHookEventSubscriber) Option {
+	return func(c *Client) error {
+		c.Hooks.AddResponseEventSubscriber(subscriber)
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the RetryPolicy of the Client.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) error {
+		c.RetryPolicy = policy
+		return nil
+	}
+}
+
+// WithRateLimiter sets the RateLimiter of the Client.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) error {
+		c.RateLimiter = limiter
+		return nil
+	}
+}
+
+// WithLogger sets the Logger of the Client.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) error {
+		c.Logger = logger
+		return nil
+	}
+}
+
+// WithMiddleware adds middlewares to the Client. See Client.Use.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) error {
+		c.Use(middlewares...)
+		return nil
+	}
+}
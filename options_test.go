@@ -0,0 +1,143 @@
+package easypost
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+// setenv sets environment variables for the duration of a test, returning a function restoring their values.
+func setenv(values map[string]string) func() {
+	previous := map[string]*string{}
+	for key, value := range values {
+		if old, ok := os.LookupEnv(key); ok {
+			previous[key] = &old
+		} else {
+			previous[key] = nil
+		}
+		if value == "" {
+			_ = os.Unsetenv(key)
+		} else {
+			_ = os.Setenv(key, value)
+		}
+	}
+	return func() {
+		for key, value := range previous {
+			if value == nil {
+				_ = os.Unsetenv(key)
+			} else {
+				_ = os.Setenv(key, *value)
+			}
+		}
+	}
+}
+
+func (c *ClientTests) TestNewWithOptions() {
+	assert, require := c.Assert(), c.Require()
+
+	httpClient := &http.Client{}
+	retryPolicy := NewRetryPolicy(3)
+	rateLimiter := NewRateLimiter(10, 1)
+	logger := &recordingLogger{}
+
+	client, err := NewWithOptions("EZTK_123",
+		WithBaseURL("https://example.com/v2"),
+		WithHTTPClient(httpClient),
+		WithDefaultTimeout(5*time.Second),
+		WithUserAgent("my-service/1.0"),
+		WithRequestHook(RequestHookEventSubscriber{HookEventSubscriber: HookEventSubscriber{ID: "hook"}}),
+		WithResponseHook(ResponseHookEventSubscriber{HookEventSubscriber: HookEventSubscriber{ID: "hook"}}),
+		WithRetryPolicy(retryPolicy),
+		WithRateLimiter(rateLimiter),
+		WithLogger(logger),
+	)
+	require.NoError(err)
+
+	assert.Equal("EZTK_123", client.APIKey)
+	assert.Equal("https://example.com/v2/", client.BaseURL.String())
+	assert.Same(httpClient, client.Client)
+	assert.Equal(5000, client.Timeout)
+	assert.Equal("my-service/1.0", client.UserAgent)
+	assert.Equal(1, len(client.Hooks.RequestHookEventSubscriptions))
+	assert.Equal(1, len(client.Hooks.ResponseHookEventSubscriptions))
+	assert.Same(retryPolicy, client.RetryPolicy)
+	assert.Same(rateLimiter, client.RateLimiter)
+	assert.Equal(logger, client.Logger)
+}
+
+func (c *ClientTests) TestNewWithOptionsValidation() {
+	assert := c.Assert()
+
+	_, err := NewWithOptions("")
+	assert.True(errors.As(err, new(*MissingPropertyError)))
+
+	_, err = NewWithOptions("EZTK_123", WithBaseURL("not a url"))
+	assert.True(errors.As(err, new(*InvalidObjectError)))
+
+	_, err = NewWithOptions("EZTK_123", WithDefaultTimeout(0))
+	assert.True(errors.As(err, new(*InvalidObjectError)))
+
+	// timeouts shorter than a millisecond are rounded up rather than falling back to the default timeout
+	client, err := NewWithOptions("EZTK_123", WithDefaultTimeout(time.Microsecond))
+	assert.NoError(err)
+	assert.Equal(1, client.Timeout)
+	assert.Equal(time.Millisecond, client.timeout())
+
+	client, err = NewWithOptions("EZTK_123", WithDefaultTimeout(1500*time.Microsecond))
+	assert.NoError(err)
+	assert.Equal(2, client.Timeout)
+}
+
+func (c *ClientTests) TestNewFromEnv() {
+	assert, require := c.Assert(), c.Require()
+
+	defer setenv(map[string]string{
+		EnvAPIKey:   "EZAK_123",
+		EnvBaseURL:  "https://example.com/v2/",
+		EnvTimeout:  "30s",
+		EnvProxyURL: "http://proxy.internal:3128",
+	})()
+
+	client, err := NewFromEnv(WithUserAgent("my-service/1.0"))
+	require.NoError(err)
+
+	assert.Equal("EZAK_123", client.APIKey)
+	assert.Equal("https://example.com/v2/", client.BaseURL.String())
+	assert.Equal(30000, client.Timeout)
+	assert.Equal("http://proxy.internal:3128", client.Proxy.String())
+	assert.Equal("my-service/1.0", client.UserAgent)
+
+	// the proxy is used by the HTTP client owned by the Client
+	transport := client.client().Transport.(*http.Transport)
+	proxyURL, err := transport.Proxy(&http.Request{URL: client.BaseURL})
+	require.NoError(err)
+	assert.Equal("http://proxy.internal:3128", proxyURL.String())
+
+	// timeouts can also be given in milliseconds
+	defer setenv(map[string]string{EnvTimeout: "1500"})()
+	client, err = NewFromEnv()
+	require.NoError(err)
+	assert.Equal(1500, client.Timeout)
+}
+
+func (c *ClientTests) TestNewFromEnvValidation() {
+	assert, require := c.Assert(), c.Require()
+
+	defer setenv(map[string]string{EnvAPIKey: "", EnvBaseURL: "", EnvTimeout: "", EnvProxyURL: ""})()
+
+	_, err := NewFromEnv()
+	var missingPropertyError *MissingPropertyError
+	require.True(errors.As(err, &missingPropertyError))
+	assert.Contains(err.Error(), EnvAPIKey)
+
+	defer setenv(map[string]string{EnvAPIKey: "EZTK_123", EnvTimeout: "soon"})()
+	_, err = NewFromEnv()
+	require.True(errors.As(err, new(*InvalidObjectError)))
+	assert.Contains(err.Error(), EnvTimeout)
+
+	defer setenv(map[string]string{EnvTimeout: "", EnvProxyURL: "proxy.internal"})()
+	_, err = NewFromEnv()
+	require.True(errors.As(err, new(*InvalidObjectError)))
+	assert.Contains(err.Error(), "proxy URL")
+}
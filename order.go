@@ -117,6 +117,12 @@ func (c *Client) BuyOrderWithContext(ctx context.Context, orderID, carrier, serv
 	return
 }
 
+// BuyOrderWithRequestOptions performs the same operation as BuyOrderWithContext,
+// applying the given request options to this call only.
+func (c *Client) BuyOrderWithRequestOptions(ctx context.Context, orderID, carrier, service string, opts ...RequestOption) (out *Order, err error) {
+	return c.BuyOrderWithContext(ContextWithRequestOptions(ctx, opts...), orderID, carrier, service)
+}
+
 // LowestOrderRate gets the lowest rate of an order
 func (c *Client) LowestOrderRate(order *Order) (out Rate, err error) {
 	return c.LowestOrderRateWithCarrier(order, nil)
@@ -78,6 +78,12 @@ func (c *Client) CreatePickupWithContext(ctx context.Context, in *Pickup) (out *
 	return
 }
 
+// CreatePickupWithRequestOptions performs the same operation as CreatePickupWithContext,
+// applying the given request options to this call only.
+func (c *Client) CreatePickupWithRequestOptions(ctx context.Context, in *Pickup, opts ...RequestOption) (out *Pickup, err error) {
+	return c.CreatePickupWithContext(ContextWithRequestOptions(ctx, opts...), in)
+}
+
 // GetPickup retrieves an existing Pickup object by ID.
 func (c *Client) GetPickup(pickupID string) (out *Pickup, err error) {
 	return c.GetPickupWithContext(context.Background(), pickupID)
@@ -110,6 +116,12 @@ func (c *Client) BuyPickupWithContext(ctx context.Context, pickupID string, rate
 	return
 }
 
+// BuyPickupWithRequestOptions performs the same operation as BuyPickupWithContext,
+// applying the given request options to this call only.
+func (c *Client) BuyPickupWithRequestOptions(ctx context.Context, pickupID string, rate *PickupRate, opts ...RequestOption) (out *Pickup, err error) {
+	return c.BuyPickupWithContext(ContextWithRequestOptions(ctx, opts...), pickupID, rate)
+}
+
 // CancelPickup cancels a scheduled pickup.
 func (c *Client) CancelPickup(pickupID string) (out *Pickup, err error) {
 	return c.CancelPickupWithContext(context.Background(), pickupID)
@@ -181,3 +193,50 @@ func (c *Client) GetNextPickupPageWithPageSizeWithContext(ctx context.Context, c
 	}
 	return c.ListPickupsWithContext(ctx, params)
 }
+
+// PickupIterator iterates over a paginated collection of pickups. See IteratePickups.
+type PickupIterator struct {
+	*pageIterator
+}
+
+// Pickup returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *PickupIterator) Pickup() *Pickup {
+	pickup, _ := it.current.(*Pickup)
+	return pickup
+}
+
+// IteratePickups returns an iterator over the pickups matching opts, fetching the pages of the collection lazily as the
+// iteration goes.
+//
+//	it := c.IteratePickups(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Pickup().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IteratePickups(opts *ListOptions, iterOpts *IteratorOptions) *PickupIterator {
+	return c.IteratePickupsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IteratePickupsWithContext performs the same operation as IteratePickups, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IteratePickupsWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *PickupIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListPickupsWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Pickups))
+		for i, pickup := range page.Pickups {
+			items[i] = pickup
+		}
+		return items, page.HasMore, nil
+	}
+	return &PickupIterator{newListIterator(ctx, "pickups", CursorBeforeID, &filters, iterOpts, list)}
+}
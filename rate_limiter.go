@@ -0,0 +1,175 @@
+package easypost
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter throttles the API calls made by a Client before they are sent, using token buckets shared by every
+// goroutine using the Client. Calls are matched against endpoint limits (in the order they were added) by their
+// path, and fall back to the default limit when no endpoint limit matches.
+//
+// A RateLimiter is safe for concurrent use and can be shared between several Clients using the same API key.
+type RateLimiter struct {
+	defaultBucket *tokenBucket
+	mu            sync.RWMutex
+	endpoints     []*endpointRateLimit
+}
+
+type endpointRateLimit struct {
+	pattern string
+	bucket  *tokenBucket
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond API calls per second on average, with bursts of up
+// to burst calls. A requestsPerSecond of 0 or less leaves calls that do not match an endpoint limit unthrottled.
+//
+//	limiter := easypost.NewRateLimiter(20, 5)
+//	limiter.SetEndpointLimit("shipments/*/buy", 5, 1)
+//	limiter.SetEndpointLimit("trackers", 10, 10)
+//	client.RateLimiter = limiter
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{defaultBucket: newTokenBucket(requestsPerSecond, burst)}
+}
+
+// SetEndpointLimit gives the endpoints matching pattern their own budget, separate from the default limit.
+//
+// The pattern is matched against the request path relative to the API base URL (e.g. "shipments/shp_123/buy") using
+// path.Match syntax, where "*" matches a single path segment. A pattern also matches every path nested below it, so
+// "trackers" matches both "trackers" and "trackers/trk_123". Setting the limit of an existing pattern replaces it.
+func (l *RateLimiter) SetEndpointLimit(pattern string, requestsPerSecond float64, burst int) {
+	pattern = strings.Trim(pattern, "/")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, endpoint := range l.endpoints {
+		if endpoint.pattern == pattern {
+			endpoint.bucket = newTokenBucket(requestsPerSecond, burst)
+			return
+		}
+	}
+	l.endpoints = append(l.endpoints, &endpointRateLimit{pattern: pattern, bucket: newTokenBucket(requestsPerSecond, burst)})
+}
+
+// Wait blocks until an API call to the given path is allowed to proceed, or until ctx is done, in which case the
+// context's error is returned.
+func (l *RateLimiter) Wait(ctx context.Context, requestPath string) error {
+	return l.bucketFor(requestPath).wait(ctx)
+}
+
+// QueueDepth returns the number of API calls currently waiting on this RateLimiter, across all endpoint limits.
+func (l *RateLimiter) QueueDepth() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	depth := l.defaultBucket.queueDepth()
+	for _, endpoint := range l.endpoints {
+		depth += endpoint.bucket.queueDepth()
+	}
+	return depth
+}
+
+// EndpointQueueDepth returns the number of API calls currently waiting on the endpoint limit set for pattern.
+func (l *RateLimiter) EndpointQueueDepth(pattern string) int {
+	pattern = strings.Trim(pattern, "/")
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, endpoint := range l.endpoints {
+		if endpoint.pattern == pattern {
+			return endpoint.bucket.queueDepth()
+		}
+	}
+	return 0
+}
+
+// bucketFor returns the token bucket that API calls to the given path draw from.
+func (l *RateLimiter) bucketFor(requestPath string) *tokenBucket {
+	requestPath = strings.Trim(requestPath, "/")
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, endpoint := range l.endpoints {
+		if matchesEndpointPattern(endpoint.pattern, requestPath) {
+			return endpoint.bucket
+		}
+	}
+	return l.defaultBucket
+}
+
+// matchesEndpointPattern returns true if the path, or one of its parent paths, matches the pattern.
+func matchesEndpointPattern(pattern, requestPath string) bool {
+	for candidate := requestPath; candidate != "."; candidate = path.Dir(candidate) {
+		if matched, _ := path.Match(pattern, candidate); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenBucket is a token bucket where waiting callers reserve tokens in arrival order.
+type tokenBucket struct {
+	waiting int64 // accessed atomically, kept first for 64-bit alignment
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+}
+
+func newTokenBucket(requestsPerSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b.rate <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	// reserve a token, going into debt if none is available so later callers queue up behind this one
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	atomic.AddInt64(&b.waiting, 1)
+	defer atomic.AddInt64(&b.waiting, -1)
+
+	if err := sleepWithContext(ctx, delay); err != nil {
+		// give the reserved token back since it was never used
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (b *tokenBucket) queueDepth() int {
+	return int(atomic.LoadInt64(&b.waiting))
+}
@@ -0,0 +1,122 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+func (c *ClientTests) TestRateLimiterThrottlesCalls() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(200, `{}`, nil))
+	client.RateLimiter = NewRateLimiter(20, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetAddress("adr_123")
+		require.NoError(err)
+	}
+
+	// the first call uses the burst, the next two wait ~50ms each
+	assert.True(time.Since(start) >= 90*time.Millisecond)
+	assert.Equal(3, *calls)
+}
+
+func (c *ClientTests) TestRateLimiterEndpointLimits() {
+	assert, require := c.Assert(), c.Require()
+
+	limiter := NewRateLimiter(0, 1)
+	limiter.SetEndpointLimit("shipments/*/buy", 0.001, 1)
+
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, nil))
+	client.RateLimiter = limiter
+
+	// the first buy uses the endpoint's only token
+	_, err := client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	// other endpoints are not affected by the exhausted buy budget
+	for i := 0; i < 5; i++ {
+		_, err = client.GetShipment("shp_123")
+		require.NoError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.BuyShipmentWithContext(ctx, "shp_456", &Rate{ID: "rate_456"}, "")
+	require.Error(err)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func (c *ClientTests) TestRateLimiterQueueDepth() {
+	assert, require := c.Assert(), c.Require()
+
+	limiter := NewRateLimiter(0.001, 1)
+	limiter.SetEndpointLimit("trackers", 0.001, 1)
+	require.NoError(limiter.Wait(context.Background(), "addresses"))
+	require.NoError(limiter.Wait(context.Background(), "trackers/trk_123"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = limiter.Wait(ctx, "trackers")
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.QueueDepth() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(3, limiter.QueueDepth())
+	assert.Equal(3, limiter.EndpointQueueDepth("trackers"))
+	assert.Equal(0, limiter.EndpointQueueDepth("shipments/*/buy"))
+
+	// waiters are released when their context is cancelled
+	cancel()
+	wg.Wait()
+	assert.Equal(0, limiter.QueueDepth())
+}
+
+func (c *ClientTests) TestRateLimiterEndpointPatterns() {
+	assert := c.Assert()
+
+	assert.True(matchesEndpointPattern("shipments/*/buy", "shipments/shp_123/buy"))
+	assert.False(matchesEndpointPattern("shipments/*/buy", "shipments/shp_123"))
+	assert.True(matchesEndpointPattern("trackers", "trackers"))
+	assert.True(matchesEndpointPattern("trackers", "trackers/trk_123"))
+	assert.False(matchesEndpointPattern("trackers", "shipments"))
+	assert.True(matchesEndpointPattern("beta/*", "beta/rates"))
+}
+
+func (c *ClientTests) TestRateLimiterSharedAcrossGoroutines() {
+	assert := c.Assert()
+
+	var mu sync.Mutex
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, nil))
+	client.RateLimiter = NewRateLimiter(100, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := 0
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ListAddresses(nil); err != nil {
+				mu.Lock()
+				errs++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 2 calls use the burst, the other 4 are spaced 10ms apart
+	assert.Equal(0, errs)
+	assert.True(time.Since(start) >= 35*time.Millisecond)
+}
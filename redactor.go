@@ -0,0 +1,88 @@
+package easypost
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// RedactedValue replaces the values of sensitive fields in logged bodies.
+const RedactedValue = "[REDACTED]"
+
+// Redactor removes sensitive fields from the JSON bodies of API calls before they are logged.
+type Redactor struct {
+	// Fields are the JSON keys whose values are redacted wherever they appear.
+	Fields []string
+	// AddressFields are the JSON keys whose values are redacted in addresses, i.e. in objects having a "street1" key.
+	AddressFields []string
+}
+
+// DefaultRedactor returns a Redactor removing the personal details of addresses, carrier account credentials,
+// credit card details, passwords and API keys.
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Fields: []string{
+			// CarrierAccount
+			"credentials", "test_credentials",
+			// CreditCardOptions
+			"number", "expiration_month", "expiration_year", "cvc",
+			// UserOptions
+			"password", "password_confirmation", "current_password",
+			// APIKey
+			"key",
+		},
+		AddressFields: []string{
+			"name", "company", "street1", "street2", "phone", "email", "federal_tax_id", "state_tax_id",
+		},
+	}
+}
+
+// Redact returns a copy of the JSON body with the values of the sensitive fields replaced by RedactedValue. A body
+// that is not valid JSON is replaced entirely, since its sensitive fields cannot be located.
+func (r *Redactor) Redact(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return []byte(RedactedValue)
+	}
+
+	redacted, err := json.Marshal(r.redact(data))
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	return redacted
+}
+
+func (r *Redactor) redact(data interface{}) interface{} {
+	switch data := data.(type) {
+	case []interface{}:
+		for i, value := range data {
+			data[i] = r.redact(value)
+		}
+	case map[string]interface{}:
+		_, isAddress := data["street1"]
+		for key, value := range data {
+			if containsString(r.Fields, key) || (isAddress && containsString(r.AddressFields, key)) {
+				if value != nil {
+					data[key] = RedactedValue
+				}
+				continue
+			}
+			data[key] = r.redact(value)
+		}
+	}
+	return data
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package easypost
+
+import (
+	"encoding/json"
+)
+
+func (c *ClientTests) TestRedactorDefaultFields() {
+	assert, require := c.Assert(), c.Require()
+
+	name, password := "Elizabeth Swann", "hunter2"
+	body, err := json.Marshal(map[string]interface{}{
+		"shipment": map[string]interface{}{
+			"to_address": &Address{Name: "Jack Sparrow", Street1: "388 Townsend St", City: "San Francisco"},
+			"parcel":     &Parcel{Weight: 10.5},
+		},
+		"carrier_account": &CarrierAccount{Type: "UpsAccount", Credentials: map[string]string{"account_number": "A1A1A1"}},
+		"credit_card":     &CreditCardOptions{Number: "4242424242424242", Cvc: "987"},
+		"user":            &UserOptions{Name: &name, Password: &password},
+	})
+	require.NoError(err)
+
+	redacted := string(DefaultRedactor().Redact(body))
+
+	for _, secret := range []string{"Jack Sparrow", "388 Townsend St", "A1A1A1", "4242424242424242", "987", "hunter2"} {
+		assert.NotContains(redacted, secret)
+	}
+	assert.Contains(redacted, "San Francisco")
+	assert.Contains(redacted, "10.5")
+	assert.Contains(redacted, "UpsAccount")
+	// only the personal details of addresses are redacted
+	assert.Contains(redacted, "Elizabeth Swann")
+}
+
+func (c *ClientTests) TestRedactorCustomFields() {
+	assert := c.Assert()
+
+	redactor := &Redactor{Fields: []string{"reference"}}
+
+	assert.Equal(`{"id":"shp_123","reference":"[REDACTED]"}`, string(redactor.Redact([]byte(`{"id": "shp_123", "reference": "order_123"}`))))
+	assert.Equal(RedactedValue, string(redactor.Redact([]byte(`not json`))))
+	assert.Empty(redactor.Redact(nil))
+}
@@ -123,6 +123,53 @@ func (c *Client) GetNextReferralCustomerPageWithPageSizeWithContext(ctx context.
 	return c.ListReferralCustomersWithContext(ctx, params)
 }
 
+// ReferralCustomerIterator iterates over a paginated collection of referral customers. See IterateReferralCustomers.
+type ReferralCustomerIterator struct {
+	*pageIterator
+}
+
+// ReferralCustomer returns the current item of the iterator, or nil before the first call to Next and after the
+// iteration stopped.
+func (it *ReferralCustomerIterator) ReferralCustomer() *ReferralCustomer {
+	customer, _ := it.current.(*ReferralCustomer)
+	return customer
+}
+
+// IterateReferralCustomers returns an iterator over the referral customers matching opts, fetching the pages of the
+// collection lazily as the iteration goes.
+//
+//	it := c.IterateReferralCustomers(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.ReferralCustomer().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateReferralCustomers(opts *ListOptions, iterOpts *IteratorOptions) *ReferralCustomerIterator {
+	return c.IterateReferralCustomersWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateReferralCustomersWithContext performs the same operation as IterateReferralCustomers, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateReferralCustomersWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *ReferralCustomerIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListReferralCustomersWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.ReferralCustomers))
+		for i, customer := range page.ReferralCustomers {
+			items[i] = customer
+		}
+		return items, page.HasMore, nil
+	}
+	return &ReferralCustomerIterator{newListIterator(ctx, "referral_customers", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // UpdateReferralCustomerEmail updates a ReferralCustomer's email address
 func (c *Client) UpdateReferralCustomerEmail(userId string, email string) (out *ReferralCustomer, err error) {
 	return c.UpdateReferralCustomerEmailWithContext(context.Background(), userId, email)
@@ -175,8 +222,9 @@ func (c *Client) AddReferralCustomerCreditCardFromStripe(referralCustomerApiKey
 // specifying a context that can interrupt the request.
 func (c *Client) AddReferralCustomerCreditCardFromStripeWithContext(ctx context.Context, referralCustomerApiKey string, paymentMethodId string, priority PaymentMethodPriority) (out *PaymentMethodObject, err error) {
 	client := &Client{
-		APIKey: referralCustomerApiKey,
-		Client: c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		APIKey:  referralCustomerApiKey,
+		Client:  c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		Timeout: c.Timeout,
 	}
 
 	params := map[string]interface{}{
@@ -199,8 +247,9 @@ func (c *Client) AddReferralCustomerBankAccountFromStripe(referralCustomerApiKey
 // specifying a context that can interrupt the request.
 func (c *Client) AddReferralCustomerBankAccountFromStripeWithContext(ctx context.Context, referralCustomerApiKey string, financialConnectionsId string, mandateData *MandateData, priority PaymentMethodPriority) (out *PaymentMethodObject, err error) {
 	client := &Client{
-		APIKey: referralCustomerApiKey,
-		Client: c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		APIKey:  referralCustomerApiKey,
+		Client:  c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		Timeout: c.Timeout,
 	}
 
 	params := map[string]interface{}{
@@ -225,6 +274,9 @@ func (c *Client) createStripeToken(ctx context.Context, stripeApiKey string, cre
 	data.Set("card[exp_year]", creditCardOptions.ExpYear)
 	data.Set("card[cvc]", creditCardOptions.Cvc)
 
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.stripe.com/v1/tokens", strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
@@ -257,8 +309,9 @@ func (c *Client) createStripeToken(ctx context.Context, stripeApiKey string, cre
 
 func (c *Client) createEasypostCreditCard(ctx context.Context, referralCustomerApiKey string, stripeToken string, priority PaymentMethodPriority) (out *PaymentMethodObject, err error) {
 	client := &Client{
-		APIKey: referralCustomerApiKey,
-		Client: c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		APIKey:  referralCustomerApiKey,
+		Client:  c.client(), // pass the current client's inner http.Client (configured to record) to the new client
+		Timeout: c.Timeout,
 	}
 
 	creditCardOptions := &easypostCreditCardCreateOptions{
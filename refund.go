@@ -78,6 +78,53 @@ func (c *Client) GetNextRefundPageWithPageSizeWithContext(ctx context.Context, c
 	return c.ListRefundsWithContext(ctx, params)
 }
 
+// RefundIterator iterates over a paginated collection of refunds. See IterateRefunds.
+type RefundIterator struct {
+	*pageIterator
+}
+
+// Refund returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *RefundIterator) Refund() *Refund {
+	refund, _ := it.current.(*Refund)
+	return refund
+}
+
+// IterateRefunds returns an iterator over the refunds matching opts, fetching the pages of the collection lazily as the
+// iteration goes.
+//
+//	it := c.IterateRefunds(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Refund().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateRefunds(opts *ListOptions, iterOpts *IteratorOptions) *RefundIterator {
+	return c.IterateRefundsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateRefundsWithContext performs the same operation as IterateRefunds, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateRefundsWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *RefundIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListRefundsWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Refunds))
+		for i, refund := range page.Refunds {
+			items[i] = refund
+		}
+		return items, page.HasMore, nil
+	}
+	return &RefundIterator{newListIterator(ctx, "refunds", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // retrieves a previously-created Refund by its ID.
 func (c *Client) GetRefund(refundID string) (out *Refund, err error) {
 	return c.GetRefundWithContext(context.Background(), refundID)
@@ -104,6 +104,53 @@ func (c *Client) GetNextReportPageWithPageSizeWithContext(ctx context.Context, c
 	return c.ListReportsWithContext(ctx, collection.Type, reportParams)
 }
 
+// ReportIterator iterates over a paginated collection of reports. See IterateReports.
+type ReportIterator struct {
+	*pageIterator
+}
+
+// Report returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *ReportIterator) Report() *Report {
+	report, _ := it.current.(*Report)
+	return report
+}
+
+// IterateReports returns an iterator over the reports of the given type, fetching the pages of the collection lazily as
+// the iteration goes.
+//
+//	it := c.IterateReports(typ, &easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Report().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateReports(typ string, opts *ListOptions, iterOpts *IteratorOptions) *ReportIterator {
+	return c.IterateReportsWithContext(context.Background(), typ, opts, iterOpts)
+}
+
+// IterateReportsWithContext performs the same operation as IterateReports, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateReportsWithContext(ctx context.Context, typ string, opts *ListOptions, iterOpts *IteratorOptions) *ReportIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListReportsWithContext(ctx, typ, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Reports))
+		for i, report := range page.Reports {
+			items[i] = report
+		}
+		return items, page.HasMore, nil
+	}
+	return &ReportIterator{newListIterator(ctx, "reports/"+typ, CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // GetReport fetches a Report object by report type and ID.
 func (c *Client) GetReport(typ, reportID string) (out *Report, err error) {
 	return c.GetReportWithContext(context.Background(), typ, reportID)
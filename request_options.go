@@ -0,0 +1,129 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestOption customizes a single API call. Request options are attached to the context passed to any *WithContext
+// method with ContextWithRequestOptions: ContextWithIdempotencyKey and ContextWithResponseMetadata are shorthands for
+// it. Purchase and create calls also have a *WithRequestOptions variant (e.g. BuyShipmentWithRequestOptions) taking the
+// options as arguments, which apply to that call only.
+type RequestOption func(o *requestOptions)
+
+type requestOptions struct {
+	headers          http.Header
+	timeout          time.Duration
+	idempotencyKey   string
+	idempotencyCall  *idempotencyKeyCall
+	basePath         string
+	responseMetadata *ResponseMetadata
+	rawResponse      *RawResponse
+
+	// capturedMetadata receives the response metadata for the library's own use, without replacing the one requested
+	// by the caller with WithResponseMetadata.
+	capturedMetadata *ResponseMetadata
+
+	allowProductionPurchase bool
+}
+
+// RawResponse is the raw response to an API call, captured with WithRawResponse.
+type RawResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the response headers.
+	Header http.Header
+	// Body is the undecoded response body.
+	Body []byte
+}
+
+type requestOptionsContextKey struct{}
+
+// ContextWithRequestOptions returns a copy of ctx that applies the given options to the API call made by any
+// *WithContext method it is passed to. Options are added to the ones already carried by ctx, including the ones set by
+// ContextWithIdempotencyKey and ContextWithResponseMetadata, and later options take precedence over earlier ones.
+//
+//	ctx := easypost.ContextWithRequestOptions(context.Background(),
+//		easypost.WithHeader("X-Correlation-ID", correlationID),
+//		easypost.WithRequestTimeout(10*time.Second),
+//	)
+//	shipment, err := client.CreateShipmentWithContext(ctx, in)
+func ContextWithRequestOptions(ctx context.Context, options ...RequestOption) context.Context {
+	existing, _ := ctx.Value(requestOptionsContextKey{}).([]RequestOption)
+	combined := make([]RequestOption, 0, len(existing)+len(options))
+	combined = append(append(combined, existing...), options...)
+	return context.WithValue(ctx, requestOptionsContextKey{}, combined)
+}
+
+// requestOptionsFromContext returns the request options carried by ctx.
+func requestOptionsFromContext(ctx context.Context) *requestOptions {
+	resolved := &requestOptions{}
+	options, _ := ctx.Value(requestOptionsContextKey{}).([]RequestOption)
+	for _, option := range options {
+		option(resolved)
+	}
+	return resolved
+}
+
+// WithHeader sets a header on the request, replacing any value set by the Client.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// WithRequestTimeout overrides the Client's Timeout, set with WithDefaultTimeout, for each attempt of the API call.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sends the given idempotency key with the request. See ContextWithIdempotencyKey.
+func WithIdempotencyKey(key string) RequestOption {
+	call := &idempotencyKeyCall{}
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+		o.idempotencyCall = call
+	}
+}
+
+// WithBasePath replaces the path of the Client's BaseURL (e.g. "/v2/") for the API call, to reach an endpoint served
+// under another version of the API (e.g. "/beta/").
+func WithBasePath(basePath string) RequestOption {
+	return func(o *requestOptions) {
+		if !strings.HasSuffix(basePath, "/") {
+			basePath += "/"
+		}
+		o.basePath = basePath
+	}
+}
+
+// WithResponseMetadata captures the metadata of the response to the API call into metadata. See
+// ContextWithResponseMetadata.
+func WithResponseMetadata(metadata *ResponseMetadata) RequestOption {
+	return func(o *requestOptions) {
+		o.responseMetadata = metadata
+	}
+}
+
+// captureResponseMetadata captures the metadata of the response into metadata alongside any WithResponseMetadata
+// option.
+func captureResponseMetadata(metadata *ResponseMetadata) RequestOption {
+	return func(o *requestOptions) {
+		o.capturedMetadata = metadata
+	}
+}
+
+// WithRawResponse captures the raw response to the API call into response, whether the call succeeds or fails, as long
+// as the API returned a response.
+func WithRawResponse(response *RawResponse) RequestOption {
+	return func(o *requestOptions) {
+		o.rawResponse = response
+	}
+}
@@ -0,0 +1,135 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestRequestOptionsHeadersAndBasePath() {
+	assert, require := c.Assert(), c.Require()
+
+	var sent *http.Request
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, nil), nil
+	})
+
+	ctx := ContextWithRequestOptions(context.Background(), WithHeader("X-Correlation-ID", "abc"))
+	ctx = ContextWithRequestOptions(ctx, WithHeader("User-Agent", "custom"), WithBasePath("/beta"))
+	_, err := client.GetShipmentWithContext(ctx, "shp_123")
+	require.NoError(err)
+
+	assert.Equal("abc", sent.Header.Get("X-Correlation-ID"))
+	assert.Equal("custom", sent.Header.Get("User-Agent"))
+	assert.Equal("/beta/shipments/shp_123", sent.URL.Path)
+
+	// options only apply to the calls made with the context carrying them
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Empty(sent.Header.Get("X-Correlation-ID"))
+	assert.Equal("/v2/shipments/shp_123", sent.URL.Path)
+}
+
+func (c *ClientTests) TestRequestOptionsIdempotencyKey() {
+	assert, require := c.Assert(), c.Require()
+
+	var sent *http.Request
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return scriptedResponse(422, `{"error": {"code": "SHIPMENT.POSTAGE.FAILURE", "message": "failed"}}`, nil), nil
+	})
+
+	ctx := ContextWithRequestOptions(context.Background(), WithIdempotencyKey("buy-shp_123"))
+	_, err := client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.Error(err)
+
+	assert.Equal("buy-shp_123", sent.Header.Get(IdempotencyKeyHeader))
+	var apiError *APIError
+	require.True(errors.As(err, &apiError))
+	assert.Equal("buy-shp_123", apiError.IdempotencyKey)
+
+	// the key set last on the context wins, whichever function set it
+	ctx = ContextWithIdempotencyKey(ctx, "retry-shp_123")
+	assert.Equal("retry-shp_123", IdempotencyKeyFromContext(ctx))
+	_, _ = client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	assert.Equal("retry-shp_123", sent.Header.Get(IdempotencyKeyHeader))
+
+	ctx = ContextWithRequestOptions(ctx, WithIdempotencyKey("final-shp_123"))
+	_, _ = client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	assert.Equal("final-shp_123", sent.Header.Get(IdempotencyKeyHeader))
+}
+
+func (c *ClientTests) TestRequestOptionsTimeout() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	client.Timeout = 60000
+
+	start := time.Now()
+	ctx := ContextWithRequestOptions(context.Background(), WithRequestTimeout(10*time.Millisecond))
+	_, err := client.GetShipmentWithContext(ctx, "shp_123")
+	require.Error(err)
+
+	var timeoutError *TimeoutError
+	assert.True(errors.As(err, &timeoutError))
+	assert.True(time.Since(start) < 10*time.Second)
+}
+
+func (c *ClientTests) TestRequestOptionsCaptureResponse() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(
+		respondWith(200, `{"id": "shp_123", "object": "Shipment"}`, map[string]string{RequestIDHeader: "req_123"}),
+		respondWith(404, `{"error": {"code": "NOT_FOUND", "message": "not found"}}`, map[string]string{RequestIDHeader: "req_456"}),
+	)
+
+	var metadata ResponseMetadata
+	var raw RawResponse
+	ctx := ContextWithRequestOptions(context.Background(), WithResponseMetadata(&metadata), WithRawResponse(&raw))
+
+	shipment, err := client.GetShipmentWithContext(ctx, "shp_123")
+	require.NoError(err)
+	assert.Equal("shp_123", shipment.ID)
+	assert.Equal("req_123", metadata.RequestID)
+	assert.Equal(200, raw.StatusCode)
+	assert.Equal("req_123", raw.Header.Get(RequestIDHeader))
+	assert.JSONEq(`{"id": "shp_123", "object": "Shipment"}`, string(raw.Body))
+
+	_, err = client.GetShipmentWithContext(ctx, "shp_123")
+	require.Error(err)
+	assert.Equal("req_456", metadata.RequestID)
+	assert.Equal(404, raw.StatusCode)
+	assert.JSONEq(`{"error": {"code": "NOT_FOUND", "message": "not found"}}`, string(raw.Body))
+}
+
+func (c *ClientTests) TestRequestOptionsPerCall() {
+	assert, require := c.Assert(), c.Require()
+
+	var sent []*http.Request
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req)
+		return scriptedResponse(200, `{"id": "shp_123", "object": "Shipment"}`, nil), nil
+	})
+
+	ctx := ContextWithRequestOptions(context.Background(), WithHeader("X-Correlation-ID", "abc"))
+	_, err := client.CreateShipmentWithRequestOptions(ctx, &Shipment{}, WithIdempotencyKey("create-shp_123"))
+	require.NoError(err)
+	_, err = client.BuyShipmentWithRequestOptions(ctx, "shp_123", &Rate{ID: "rate_123"}, "", WithIdempotencyKey("buy-shp_123"))
+	require.NoError(err)
+	_, err = client.GetShipmentWithContext(ctx, "shp_123")
+	require.NoError(err)
+
+	// options given to a call apply to it alone, on top of the ones carried by the context
+	require.Len(sent, 3)
+	assert.Equal("create-shp_123", sent[0].Header.Get(IdempotencyKeyHeader))
+	assert.Equal("buy-shp_123", sent[1].Header.Get(IdempotencyKeyHeader))
+	assert.Empty(sent[2].Header.Get(IdempotencyKeyHeader))
+	for _, req := range sent {
+		assert.Equal("abc", req.Header.Get("X-Correlation-ID"))
+	}
+}
@@ -0,0 +1,72 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseMetadataHeaders are the response headers kept in ResponseMetadata.
+var ResponseMetadataHeaders = []string{
+	RequestIDHeader,
+	"Retry-After",
+	"X-Backend",
+	"X-Node",
+	"X-Proxied",
+	"X-Runtime",
+	"X-Version-Label",
+}
+
+// ResponseMetadata describes the response the API returned for an API call. Quote the RequestID when contacting
+// EasyPost support about a specific API call.
+//
+// It is available on the APIError returned by a failed API call, and can be captured for any API call with
+// ContextWithResponseMetadata.
+type ResponseMetadata struct {
+	// RequestID is the ID the API assigned to the request.
+	RequestID string
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the path of the request URL.
+	Path string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Duration is the time taken by the API call, including every retry attempt.
+	Duration time.Duration
+	// Headers are the response headers listed in ResponseMetadataHeaders.
+	Headers http.Header
+}
+
+// ContextWithResponseMetadata returns a copy of ctx that captures the metadata of the response to the API call made by
+// any *WithContext method it is passed to into metadata. The metadata is captured whether the API call succeeds or
+// fails, as long as the API returned a response.
+//
+//	var metadata easypost.ResponseMetadata
+//	ctx := easypost.ContextWithResponseMetadata(context.Background(), &metadata)
+//	shipment, err := client.BuyShipmentWithContext(ctx, shipmentID, rate, "")
+//	log.Printf("bought shipment %s in request %s", shipment.ID, metadata.RequestID)
+//
+// It is a shorthand for ContextWithRequestOptions with WithResponseMetadata, so the metadata is captured into the
+// ResponseMetadata set last on ctx, by either of them.
+func ContextWithResponseMetadata(ctx context.Context, metadata *ResponseMetadata) context.Context {
+	return ContextWithRequestOptions(ctx, WithResponseMetadata(metadata))
+}
+
+// newResponseMetadata returns the metadata of the response to a request.
+func newResponseMetadata(req *http.Request, res *http.Response, duration time.Duration) *ResponseMetadata {
+	headers := http.Header{}
+	for _, name := range ResponseMetadataHeaders {
+		if values := res.Header.Values(name); len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+
+	return &ResponseMetadata{
+		RequestID:  res.Header.Get(RequestIDHeader),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: res.StatusCode,
+		Duration:   duration,
+		Headers:    headers,
+	}
+}
@@ -0,0 +1,63 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+func (c *ClientTests) TestResponseMetadataOnAPIError() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(404, `{"error": {"code": "NOT_FOUND", "message": "not found"}}`, map[string]string{
+		RequestIDHeader: "req_123",
+		"X-Node":        "bigweb1",
+		"Set-Cookie":    "session=secret",
+	}))
+
+	_, err := client.GetShipment("shp_123")
+	require.Error(err)
+
+	var apiError *APIError
+	require.True(errors.As(err, &apiError))
+	require.NotNil(apiError.Metadata)
+	assert.Equal("req_123", apiError.Metadata.RequestID)
+	assert.Equal(http.MethodGet, apiError.Metadata.Method)
+	assert.Equal("/v2/shipments/shp_123", apiError.Metadata.Path)
+	assert.Equal(404, apiError.Metadata.StatusCode)
+	assert.True(apiError.Metadata.Duration > 0)
+	assert.Equal("bigweb1", apiError.Metadata.Headers.Get("X-Node"))
+	assert.Empty(apiError.Metadata.Headers.Get("Set-Cookie"))
+}
+
+func (c *ClientTests) TestResponseMetadataFromContext() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(200, `{"id": "shp_123", "object": "Shipment"}`, map[string]string{RequestIDHeader: "req_123"}))
+
+	var metadata ResponseMetadata
+	ctx := ContextWithResponseMetadata(context.Background(), &metadata)
+	shipment, err := client.BuyShipmentWithContext(ctx, "shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	assert.Equal("shp_123", shipment.ID)
+	assert.Equal("req_123", metadata.RequestID)
+	assert.Equal(http.MethodPost, metadata.Method)
+	assert.Equal("/v2/shipments/shp_123/buy", metadata.Path)
+	assert.Equal(200, metadata.StatusCode)
+}
+
+func (c *ClientTests) TestResponseMetadataNotCapturedWithoutResponse() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	var metadata ResponseMetadata
+	ctx := ContextWithResponseMetadata(context.Background(), &metadata)
+	_, err := client.GetShipmentWithContext(ctx, "shp_123")
+	require.Error(err)
+
+	assert.Equal(ResponseMetadata{}, metadata)
+}
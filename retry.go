@@ -0,0 +1,175 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// RetryPolicy configures how a Client retries API calls that fail with a transient error.
+//
+// Retries are disabled when a Client has no RetryPolicy set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a single API call, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Each subsequent retry doubles the delay.
+	// If zero, a default of 500 milliseconds will be used.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay computed from InitialBackoff. If zero, a default of 30 seconds will be used.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (between 0 and 1) of each delay that is randomized to spread out retries
+	// from concurrent callers. A value of 0 disables jitter.
+	Jitter float64
+	// IgnoreRetryAfter disables honoring the Retry-After header sent with 429 and 503 responses.
+	IgnoreRetryAfter bool
+	// ShouldRetry decides whether a failed attempt should be retried. If nil, DefaultShouldRetry will be used.
+	ShouldRetry func(req *http.Request, err error) bool
+}
+
+// NewRetryPolicy returns a RetryPolicy with the given maximum number of attempts and the default backoff settings.
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Jitter:         0.2,
+	}
+}
+
+// DefaultShouldRetry is the retry decision used when a RetryPolicy does not set ShouldRetry.
+//
+// Only idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) and POST requests carrying an Idempotency-Key header
+// are retried, and only when IsRetryable returns true for the error.
+func DefaultShouldRetry(req *http.Request, err error) bool {
+	if !isIdempotentRequest(req) {
+		return false
+	}
+
+	return IsRetryable(err)
+}
+
+// isIdempotentRequest returns true if the request can safely be sent more than once.
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(IdempotencyKeyHeader) != ""
+	}
+}
+
+// isTransportError returns true if the API could not be reached or did not answer in time, i.e. the error is a
+// ConnectionError or a TimeoutError. SSLErrors and ProxyErrors point to a configuration problem that trying again will
+// not fix.
+func isTransportError(err error) bool {
+	var connectionError *ConnectionError
+	var timeoutError *TimeoutError
+	return errors.As(err, &connectionError) || errors.As(err, &timeoutError)
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, err error, attempt int) bool {
+	if attempt >= p.maxAttempts() {
+		return false
+	}
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(req, err)
+	}
+	return DefaultShouldRetry(req, err)
+}
+
+// backoff returns the delay to wait before the attempt following the given (1-based) attempt.
+func (p *RetryPolicy) backoff(attempt int, err error, retryAfter time.Duration) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	maximum := p.MaxBackoff
+	if maximum <= 0 {
+		maximum = defaultRetryMaxBackoff
+	}
+
+	delay := initial
+	for i := 1; i < attempt && delay < maximum; i++ {
+		delay *= 2
+	}
+	if delay > maximum {
+		delay = maximum
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		// #nosec G404 -- jitter does not need a cryptographically secure source
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	if !p.IgnoreRetryAfter && retryAfter > delay {
+		var rateLimitError *RateLimitError
+		var serviceUnavailableError *ServiceUnavailableError
+		if errors.As(err, &rateLimitError) || errors.As(err, &serviceUnavailableError) {
+			delay = retryAfter
+		}
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// withContextError returns the error of the last attempt of a request whose retries were interrupted by the caller's
+// context, keeping its type and details, and makes the context error reachable through errors.Is when the error has
+// no cause of its own.
+func withContextError(err, ctxErr error) error {
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.cause == nil {
+		apiError.cause = ctxErr
+	}
+	return err
+}
+
+// sleepWithContext waits for the given duration, returning early with the context's error if it is done first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
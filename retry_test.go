@@ -0,0 +1,287 @@
+package easypost
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper that delegates to a function, used to script transport behavior in tests.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// scriptedResponse builds an HTTP response with the given status code, body and headers.
+func scriptedResponse(statusCode int, body string, headers map[string]string) *http.Response {
+	header := make(http.Header)
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// ScriptedClient sets up a client whose transport answers each request with the next response in the script.
+// The last response is repeated once the script is exhausted. The returned counter tracks the number of requests sent.
+func (c *ClientTests) ScriptedClient(script ...func(req *http.Request) (*http.Response, error)) (*Client, *int) {
+	calls := 0
+	var mu sync.Mutex
+	client := &Client{
+		APIKey: "cannot_be_blank",
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			index := calls
+			if index >= len(script) {
+				index = len(script) - 1
+			}
+			calls++
+			mu.Unlock()
+			return script[index](req)
+		})},
+	}
+	return client, &calls
+}
+
+func respondWith(statusCode int, body string, headers map[string]string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		return scriptedResponse(statusCode, body, headers), nil
+	}
+}
+
+func (c *ClientTests) TestRetryTransientErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(
+		respondWith(429, `{"error": {"code": "RATE_LIMITED", "message": "slow down"}}`, nil),
+		respondWith(503, `{}`, nil),
+		respondWith(200, `{"id": "adr_123", "object": "Address"}`, nil),
+	)
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	var attempts []int
+	client.Hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event RequestHookEvent) error {
+			attempts = append(attempts, event.Attempt)
+			return nil
+		},
+	})
+
+	address, err := client.GetAddress("adr_123")
+	require.NoError(err)
+	assert.Equal("adr_123", address.ID)
+	assert.Equal(3, *calls)
+	assert.Equal([]int{1, 2, 3}, attempts)
+}
+
+func (c *ClientTests) TestRetryStopsAtMaxAttempts() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(504, `{}`, nil))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	var gatewayTimeoutError *GatewayTimeoutError
+	assert.True(errors.As(err, &gatewayTimeoutError))
+	assert.Equal(2, *calls)
+}
+
+func (c *ClientTests) TestRetrySkipsNonIdempotentRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(503, `{}`, nil))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	_, err := client.CreateParcel(&Parcel{Weight: 10})
+	require.Error(err)
+	assert.Equal(1, *calls)
+}
+
+func (c *ClientTests) TestRetrySkipsClientErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(404, `{}`, nil))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	assert.Equal(1, *calls)
+}
+
+func (c *ClientTests) TestRetryTransportErrors() {
+	assert := c.Assert()
+
+	for _, test := range []struct {
+		err   error
+		calls int
+	}{
+		{errors.New("connection reset by peer"), 2},
+		{context.DeadlineExceeded, 2},
+		// SSL and proxy failures will not go away by trying again
+		{x509.UnknownAuthorityError{}, 1},
+		{&net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}, 1},
+	} {
+		transportErr := test.err
+		client, calls := c.ScriptedClient(
+			func(req *http.Request) (*http.Response, error) {
+				return nil, transportErr
+			},
+			respondWith(200, `{"id": "adr_123", "object": "Address"}`, nil),
+		)
+		client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+		_, _ = client.GetAddress("adr_123")
+		assert.Equal(test.calls, *calls, transportErr.Error())
+	}
+}
+
+func (c *ClientTests) TestRetryCustomDecision() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(500, `{}`, nil))
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Millisecond,
+		ShouldRetry: func(req *http.Request, err error) bool {
+			var internalServerError *InternalServerError
+			return errors.As(err, &internalServerError)
+		},
+	}
+
+	_, err := client.CreateParcel(&Parcel{Weight: 10})
+	require.Error(err)
+	assert.Equal(4, *calls)
+}
+
+func (c *ClientTests) TestRetryReplaysRequestBody() {
+	assert, require := c.Assert(), c.Require()
+
+	var bodies []string
+	record := func(statusCode int) func(req *http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			return scriptedResponse(statusCode, `{}`, nil), nil
+		}
+	}
+	client, _ := c.ScriptedClient(record(503), record(200))
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		ShouldRetry: func(req *http.Request, err error) bool {
+			return true
+		},
+	}
+
+	_, err := client.UpdateWebhook("hook_123", &CreateUpdateWebhookOptions{WebhookSecret: "secret"})
+	require.NoError(err)
+	require.Equal(2, len(bodies))
+	assert.NotEmpty(bodies[0])
+	assert.Equal(bodies[0], bodies[1])
+}
+
+func (c *ClientTests) TestRetryBackoff() {
+	assert := c.Assert()
+
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(100*time.Millisecond, policy.backoff(1, nil, 0))
+	assert.Equal(200*time.Millisecond, policy.backoff(2, nil, 0))
+	assert.Equal(400*time.Millisecond, policy.backoff(3, nil, 0))
+	assert.Equal(time.Second, policy.backoff(10, nil, 0))
+
+	// Retry-After is only honored for rate limit and service unavailable errors
+	rateLimitError := &RateLimitError{APIError{StatusCode: 429}}
+	assert.Equal(5*time.Second, policy.backoff(1, rateLimitError, 5*time.Second))
+	assert.Equal(100*time.Millisecond, policy.backoff(1, &InternalServerError{}, 5*time.Second))
+	policy.IgnoreRetryAfter = true
+	assert.Equal(100*time.Millisecond, policy.backoff(1, rateLimitError, 5*time.Second))
+
+	policy = &RetryPolicy{InitialBackoff: 100 * time.Millisecond, Jitter: 0.5}
+	for i := 0; i < 10; i++ {
+		delay := policy.backoff(1, nil, 0)
+		assert.True(delay > 50*time.Millisecond && delay <= 100*time.Millisecond)
+	}
+}
+
+func (c *ClientTests) TestParseRetryAfter() {
+	assert := c.Assert()
+
+	assert.Equal(3*time.Second, parseRetryAfter("3"))
+	assert.Equal(time.Duration(0), parseRetryAfter(""))
+	assert.Equal(time.Duration(0), parseRetryAfter("soon"))
+
+	delay := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(delay > 50*time.Second && delay <= time.Minute)
+}
+
+func (c *ClientTests) TestRetryContextCancellation() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(respondWith(503, `{}`, nil))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetAddressWithContext(ctx, "adr_123")
+	require.Error(err)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(1, *calls)
+	// the backoff goes past the deadline, so the call gives up at once with the error of the attempt
+	assert.Less(time.Since(start), 15*time.Millisecond)
+	var serviceUnavailableError *ServiceUnavailableError
+	assert.True(errors.As(err, &serviceUnavailableError))
+}
+
+func (c *ClientTests) TestRetryKeepsLastErrorWhenContextEnds() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(429, `{"error": {"code": "RATE_LIMITED", "message": "slow down"}}`, map[string]string{"Retry-After": "10"}))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	// Retry-After is past the deadline of the context: no retry, and the rate limit error is returned
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetAddressWithContext(ctx, "adr_123")
+	var rateLimitError *RateLimitError
+	require.True(errors.As(err, &rateLimitError))
+	assert.Equal("10", rateLimitError.Metadata.Headers.Get("Retry-After"))
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+
+	// the context is canceled while waiting to retry
+	client.RetryPolicy.IgnoreRetryAfter = true
+	client.RetryPolicy.InitialBackoff = time.Hour
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = client.GetAddressWithContext(ctx, "adr_123")
+	require.True(errors.As(err, &rateLimitError))
+	assert.True(errors.Is(err, context.Canceled))
+
+	// an attempt timing out under the caller's deadline is still a TimeoutError
+	client, calls := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetAddressWithContext(ctx, "adr_123")
+	var timeoutError *TimeoutError
+	assert.True(errors.As(err, &timeoutError), "%T", err)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(1, *calls)
+}
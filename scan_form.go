@@ -99,6 +99,53 @@ func (c *Client) GetNextScanFormPageWithPageSizeWithContext(ctx context.Context,
 	return c.ListScanFormsWithContext(ctx, params)
 }
 
+// ScanFormIterator iterates over a paginated collection of scan forms. See IterateScanForms.
+type ScanFormIterator struct {
+	*pageIterator
+}
+
+// ScanForm returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *ScanFormIterator) ScanForm() *ScanForm {
+	scanForm, _ := it.current.(*ScanForm)
+	return scanForm
+}
+
+// IterateScanForms returns an iterator over the scan forms matching opts, fetching the pages of the collection lazily
+// as the iteration goes.
+//
+//	it := c.IterateScanForms(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.ScanForm().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateScanForms(opts *ListOptions, iterOpts *IteratorOptions) *ScanFormIterator {
+	return c.IterateScanFormsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateScanFormsWithContext performs the same operation as IterateScanForms, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateScanFormsWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *ScanFormIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListScanFormsWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.ScanForms))
+		for i, scanForm := range page.ScanForms {
+			items[i] = scanForm
+		}
+		return items, page.HasMore, nil
+	}
+	return &ScanFormIterator{newListIterator(ctx, "scan_forms", CursorBeforeID, &filters, iterOpts, list)}
+}
+
 // GetScanForm retrieves a ScanForm object by ID.
 func (c *Client) GetScanForm(scanFormID string) (out *ScanForm, err error) {
 	return c.GetScanFormWithContext(context.Background(), scanFormID)
@@ -2,7 +2,9 @@ package easypost
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"reflect"
 )
 
 // A Form represents a form associated with a Shipment.
@@ -178,6 +180,12 @@ func (c *Client) CreateShipmentWithContext(ctx context.Context, in *Shipment) (o
 	return
 }
 
+// CreateShipmentWithRequestOptions performs the same operation as CreateShipmentWithContext,
+// applying the given request options to this call only.
+func (c *Client) CreateShipmentWithRequestOptions(ctx context.Context, in *Shipment, opts ...RequestOption) (out *Shipment, err error) {
+	return c.CreateShipmentWithContext(ContextWithRequestOptions(ctx, opts...), in)
+}
+
 // ListShipments provides a paginated result of Shipment objects.
 func (c *Client) ListShipments(opts *ListShipmentsOptions) (out *ListShipmentsResult, err error) {
 	return c.ListShipmentsWithContext(context.Background(), opts)
@@ -234,6 +242,112 @@ func (c *Client) GetNextShipmentPageWithPageSizeWithContext(ctx context.Context,
 	return c.ListShipmentsWithContext(ctx, shipmentParams)
 }
 
+// ShipmentIterator iterates over a paginated collection of shipments. See IterateShipments.
+type ShipmentIterator struct {
+	*pageIterator
+}
+
+// Shipment returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *ShipmentIterator) Shipment() *Shipment {
+	shipment, _ := it.current.(*Shipment)
+	return shipment
+}
+
+// IterateShipments returns an iterator over the shipments matching opts, fetching the pages of the collection lazily as
+// the iteration goes.
+//
+//	it := c.IterateShipments(&easypost.ListShipmentsOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Shipment().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateShipments(opts *ListShipmentsOptions, iterOpts *IteratorOptions) *ShipmentIterator {
+	return c.IterateShipmentsWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateShipmentsWithContext performs the same operation as IterateShipments, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateShipmentsWithContext(ctx context.Context, opts *ListShipmentsOptions, iterOpts *IteratorOptions) *ShipmentIterator {
+	var filters ListShipmentsOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListShipmentsWithContext(ctx, opts.(*ListShipmentsOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Shipments))
+		for i, shipment := range page.Shipments {
+			items[i] = shipment
+		}
+		return items, page.HasMore, nil
+	}
+	return &ShipmentIterator{newListIterator(ctx, "shipments", CursorBeforeID, &filters, iterOpts, list)}
+}
+
+// ListShipmentsInDateRange returns every shipment created between the StartDateTime and EndDateTime of opts, newest
+// first. The range is split into windows that are paged through concurrently as configured by rangeOpts, and shipments
+// returned by more than one window are only included once. The other filters of opts (e.g. Purchased and
+// IncludeChildren) apply to every window.
+func (c *Client) ListShipmentsInDateRange(opts *ListShipmentsOptions, rangeOpts *DateRangeOptions) (out []*Shipment, err error) {
+	return c.ListShipmentsInDateRangeWithContext(context.Background(), opts, rangeOpts)
+}
+
+// ListShipmentsInDateRangeWithContext performs the same operation as ListShipmentsInDateRange, but
+// allows specifying a context that can interrupt the requests.
+func (c *Client) ListShipmentsInDateRangeWithContext(ctx context.Context, opts *ListShipmentsOptions, rangeOpts *DateRangeOptions) (out []*Shipment, err error) {
+	if opts == nil {
+		opts = &ListShipmentsOptions{}
+	}
+
+	fetch := func(ctx context.Context, start, end *DateTime) ([]interface{}, error) {
+		windowOpts := *opts
+		windowOpts.BeforeID, windowOpts.AfterID = "", ""
+		windowOpts.StartDateTime, windowOpts.EndDateTime = start, end
+
+		var items []interface{}
+		it := c.IterateShipmentsWithContext(ctx, &windowOpts, nil)
+		for it.Next() {
+			items = append(items, it.Shipment())
+		}
+		return items, it.Err()
+	}
+	idOf := func(item interface{}) string {
+		if shipment := item.(*Shipment); shipment != nil {
+			return shipment.ID
+		}
+		return ""
+	}
+
+	items, err := fetchDateRange(ctx, opts.StartDateTime, opts.EndDateTime, rangeOpts, fetch, idOf)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]*Shipment, len(items))
+	for i, item := range items {
+		out[i] = item.(*Shipment)
+	}
+	return out, nil
+}
+
+// ExportShipments streams every shipment matching opts to w in the format set by exportOpts, returning the number of
+// shipments written. The shipments are fetched one page at a time as they are written, so memory use does not grow
+// with the size of the collection; set the StartDateTime and EndDateTime of opts to export a date range.
+func (c *Client) ExportShipments(w io.Writer, opts *ListShipmentsOptions, exportOpts *ExportOptions) (count int, err error) {
+	return c.ExportShipmentsWithContext(context.Background(), w, opts, exportOpts)
+}
+
+// ExportShipmentsWithContext performs the same operation as ExportShipments, but
+// allows specifying a context that can interrupt the export.
+func (c *Client) ExportShipmentsWithContext(ctx context.Context, w io.Writer, opts *ListShipmentsOptions, exportOpts *ExportOptions) (count int, err error) {
+	it := c.IterateShipmentsWithContext(ctx, opts, nil)
+	return export(w, exportOpts, reflect.TypeOf(Shipment{}), defaultShipmentExportColumns, it.pageIterator)
+}
+
 // GetShipment retrieves a Shipment object by ID.
 func (c *Client) GetShipment(shipmentID string) (out *Shipment, err error) {
 	return c.GetShipmentWithContext(context.Background(), shipmentID)
@@ -248,6 +362,9 @@ func (c *Client) GetShipmentWithContext(ctx context.Context, shipmentID string)
 
 func (c *Client) buyShipment(ctx context.Context, shipmentID string, in *buyShipmentRequest) (out *Shipment, err error) {
 	err = c.do(ctx, http.MethodPost, "shipments/"+shipmentID+"/buy", &in, &out)
+	if err == nil {
+		c.recordSpend(out)
+	}
 	return
 }
 
@@ -267,6 +384,12 @@ func (c *Client) BuyShipmentWithContext(ctx context.Context, shipmentID string,
 	return c.buyShipment(ctx, shipmentID, req)
 }
 
+// BuyShipmentWithRequestOptions performs the same operation as BuyShipmentWithContext,
+// applying the given request options to this call only.
+func (c *Client) BuyShipmentWithRequestOptions(ctx context.Context, shipmentID string, rate *Rate, insurance string, opts ...RequestOption) (out *Shipment, err error) {
+	return c.BuyShipmentWithContext(ContextWithRequestOptions(ctx, opts...), shipmentID, rate, insurance)
+}
+
 // BuyShipmentWithEndShipper performs the same operation as BuyShipment, but includes an EndShipper ID.
 func (c *Client) BuyShipmentWithEndShipper(shipmentID string, rate *Rate, insurance string, endShipperID string) (out *Shipment, err error) {
 	return c.BuyShipmentWithEndShipperWithContext(context.Background(), shipmentID, rate, insurance, endShipperID)
@@ -0,0 +1,189 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span attributes set by a Client on the span of each API call.
+const (
+	SpanAttributeHTTPMethod     = "http.method"
+	SpanAttributeHTTPRoute      = "http.route"
+	SpanAttributeHTTPStatusCode = "http.status_code"
+	SpanAttributeErrorType      = "error.type"
+	SpanAttributeRequestID      = "easypost.request_id"
+)
+
+// Tracer opens the spans traced by a Client. A Client opens one span per API call, covering every retry attempt, and
+// sets the SpanAttribute* attributes on it.
+//
+// Implementations should make the span a child of the span carried by ctx, if any, and return a context carrying the
+// new span: it is passed down to the hooks, middlewares and HTTP requests of the API call, which lets the trace
+// context propagate to them. An adapter for OpenTelemetry only takes a few lines:
+//
+//	type otelTracer struct{ tracer trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, easypost.Span) {
+//		ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span opened by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute on the span. The value is a string or an int.
+	SetAttribute(key string, value interface{})
+	// RecordError records the error the API call failed with.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// NoopTracer is a Tracer whose spans do nothing. It is used by a Client that has no Tracer.
+type NoopTracer struct{}
+
+// Start returns ctx unchanged and a Span that does nothing.
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+// InMemoryTracer is a Tracer keeping its spans in memory, to inspect the spans of a Client in tests.
+// An InMemoryTracer is safe for concurrent use.
+type InMemoryTracer struct {
+	mu    sync.Mutex
+	spans []*InMemorySpan
+}
+
+// NewInMemoryTracer returns a new InMemoryTracer.
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+type inMemorySpanContextKey struct{}
+
+// Start opens a new InMemorySpan, as a child of the InMemorySpan carried by ctx, if any.
+func (t *InMemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(inMemorySpanContextKey{}).(*InMemorySpan)
+	span := &InMemorySpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: map[string]interface{}{},
+		StartTime:  time.Now(),
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, inMemorySpanContextKey{}, span), span
+}
+
+// Spans returns the spans opened by the tracer, in the order they were opened.
+func (t *InMemoryTracer) Spans() []*InMemorySpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*InMemorySpan(nil), t.spans...)
+}
+
+// Reset forgets the spans opened so far.
+func (t *InMemoryTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+// InMemorySpan is a Span opened by an InMemoryTracer.
+type InMemorySpan struct {
+	Name       string
+	Parent     *InMemorySpan
+	Attributes map[string]interface{}
+	Err        error
+	StartTime  time.Time
+	EndTime    time.Time
+
+	mu sync.Mutex
+}
+
+// SetAttribute sets an attribute on the span.
+func (s *InMemorySpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError records the error the API call failed with.
+func (s *InMemorySpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// End ends the span.
+func (s *InMemorySpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.EndTime = time.Now()
+}
+
+// Ended returns true if the span has ended.
+func (s *InMemorySpan) Ended() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.EndTime.IsZero()
+}
+
+func (c *Client) tracer() Tracer {
+	if c.Tracer != nil {
+		return c.Tracer
+	}
+	return NoopTracer{}
+}
+
+// idPathSegment matches the object IDs found in endpoint paths, e.g. "shp_123abc".
+var idPathSegment = regexp.MustCompile(`^[a-z]+_\w*\d\w*$`)
+
+// templatePath returns the route of an endpoint path, with its object IDs replaced by "{id}" (e.g.
+// "shipments/{id}/buy").
+func templatePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if idPathSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// ErrorTypeOther is the error type reported for errors that are not, and do not wrap, one of the library's errors.
+const ErrorTypeOther = "other"
+
+// libraryPackagePath is the import path of the library, which its error types belong to.
+var libraryPackagePath = reflect.TypeOf(LibraryError{}).PkgPath()
+
+// errorTypeName returns the name of the type of the first of the library's errors found in the chain of err, e.g.
+// "NotFoundError", or ErrorTypeOther if there is none, so that it can be used as a metric or span label.
+func errorTypeName(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		typ := reflect.TypeOf(err)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.PkgPath() == libraryPackagePath && typ.Name() != "" {
+			return typ.Name()
+		}
+	}
+	return ErrorTypeOther
+}
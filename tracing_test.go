@@ -0,0 +1,128 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (c *ClientTests) TestTracerSpanPerAPICall() {
+	assert, require := c.Assert(), c.Require()
+
+	client, calls := c.ScriptedClient(
+		respondWith(503, `{}`, nil),
+		respondWith(200, `{"id": "shp_123", "object": "Shipment"}`, map[string]string{RequestIDHeader: "req_123"}),
+	)
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, ShouldRetry: func(req *http.Request, err error) bool {
+		return true
+	}}
+	tracer := NewInMemoryTracer()
+	client.Tracer = tracer
+
+	_, err := client.BuyShipment("shp_123", &Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	// a single span covers every attempt of the call
+	assert.Equal(2, *calls)
+	spans := tracer.Spans()
+	require.Equal(1, len(spans))
+	span := spans[0]
+	assert.Equal("POST shipments/{id}/buy", span.Name)
+	assert.Equal(http.MethodPost, span.Attributes[SpanAttributeHTTPMethod])
+	assert.Equal("shipments/{id}/buy", span.Attributes[SpanAttributeHTTPRoute])
+	assert.Equal(200, span.Attributes[SpanAttributeHTTPStatusCode])
+	assert.Equal("req_123", span.Attributes[SpanAttributeRequestID])
+	assert.NotContains(span.Attributes, SpanAttributeErrorType)
+	assert.Nil(span.Err)
+	assert.True(span.Ended())
+}
+
+func (c *ClientTests) TestTracerRecordsErrorType() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(respondWith(404, `{"error": {"code": "NOT_FOUND", "message": "not found"}}`, map[string]string{RequestIDHeader: "req_404"}))
+	tracer := NewInMemoryTracer()
+	client.Tracer = tracer
+
+	_, err := client.GetTracker("trk_123")
+	require.Error(err)
+
+	span := tracer.Spans()[0]
+	assert.Equal("NotFoundError", span.Attributes[SpanAttributeErrorType])
+	assert.Equal(404, span.Attributes[SpanAttributeHTTPStatusCode])
+	assert.Equal("req_404", span.Attributes[SpanAttributeRequestID])
+	assert.Equal(err, span.Err)
+	assert.True(span.Ended())
+}
+
+func (c *ClientTests) TestTracerPropagatesContext() {
+	assert, require := c.Assert(), c.Require()
+
+	tracer := NewInMemoryTracer()
+	var hookSpan *InMemorySpan
+	client, _ := c.ScriptedClient(respondWith(200, `{}`, nil))
+	client.Tracer = tracer
+	client.Hooks.AddRequestEventSubscriber(RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event RequestHookEvent) error {
+			_, span := tracer.Start(ctx, "hook")
+			hookSpan = span.(*InMemorySpan)
+			span.End()
+			return nil
+		},
+	})
+
+	ctx, parent := tracer.Start(context.Background(), "process order")
+	_, err := client.GetAddressWithContext(ctx, "adr_123")
+	require.NoError(err)
+	parent.End()
+
+	spans := tracer.Spans()
+	require.Equal(3, len(spans))
+	callSpan := spans[1]
+	assert.Equal("GET addresses/{id}", callSpan.Name)
+	assert.Same(parent, callSpan.Parent)
+	assert.Same(callSpan, hookSpan.Parent)
+}
+
+func (c *ClientTests) TestTracerTransportError() {
+	assert, require := c.Assert(), c.Require()
+
+	client, _ := c.ScriptedClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	tracer := NewInMemoryTracer()
+	client.Tracer = tracer
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	span := tracer.Spans()[0]
+	assert.NotContains(span.Attributes, SpanAttributeHTTPStatusCode)
+	assert.Equal("ConnectionError", span.Attributes[SpanAttributeErrorType])
+	assert.NotNil(span.Err)
+}
+
+func (c *ClientTests) TestErrorTypeName() {
+	assert := c.Assert()
+
+	notFound := &NotFoundError{APIError: APIError{StatusCode: 404}}
+	assert.Equal("NotFoundError", errorTypeName(notFound))
+	assert.Equal("NotFoundError", errorTypeName(fmt.Errorf("getting tracker: %w", notFound)))
+	assert.Equal("MissingPropertyError", errorTypeName(newMissingPropertyError("APIKey")))
+
+	// errors outside of the library's hierarchy get a fixed label, to keep the label set bounded
+	assert.Equal(ErrorTypeOther, errorTypeName(fmt.Errorf("mocking: %w", errors.New("no match"))))
+	assert.Equal(ErrorTypeOther, errorTypeName(context.Canceled))
+}
+
+func (c *ClientTests) TestTemplatePath() {
+	assert := c.Assert()
+
+	assert.Equal("shipments/{id}/buy", templatePath("shipments/shp_1a2b3c/buy"))
+	assert.Equal("beta/rates", templatePath("/beta/rates"))
+	assert.Equal("carrier_accounts/{id}", templatePath("carrier_accounts/ca_123"))
+	assert.Equal("users/{id}/api_keys", templatePath("users/user_abc123/api_keys"))
+	assert.Equal("scan_forms", templatePath("scan_forms"))
+}
@@ -2,7 +2,9 @@ package easypost
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"reflect"
 )
 
 // TrackingLocation provides additional information about the location of a
@@ -155,6 +157,12 @@ func (c *Client) CreateTrackerWithContext(ctx context.Context, opts *CreateTrack
 	return
 }
 
+// CreateTrackerWithRequestOptions performs the same operation as CreateTrackerWithContext,
+// applying the given request options to this call only.
+func (c *Client) CreateTrackerWithRequestOptions(ctx context.Context, in *CreateTrackerOptions, opts ...RequestOption) (out *Tracker, err error) {
+	return c.CreateTrackerWithContext(ContextWithRequestOptions(ctx, opts...), in)
+}
+
 // ListTrackers provides a paginated result of Tracker objects.
 func (c *Client) ListTrackers(opts *ListTrackersOptions) (out *ListTrackersResult, err error) {
 	return c.ListTrackersWithContext(context.Background(), opts)
@@ -211,6 +219,112 @@ func (c *Client) GetNextTrackerPageWithPageSizeWithContext(ctx context.Context,
 	return c.ListTrackersWithContext(ctx, trackerParams)
 }
 
+// TrackerIterator iterates over a paginated collection of trackers. See IterateTrackers.
+type TrackerIterator struct {
+	*pageIterator
+}
+
+// Tracker returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *TrackerIterator) Tracker() *Tracker {
+	tracker, _ := it.current.(*Tracker)
+	return tracker
+}
+
+// IterateTrackers returns an iterator over the trackers matching opts, fetching the pages of the collection lazily as
+// the iteration goes.
+//
+//	it := c.IterateTrackers(&easypost.ListTrackersOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.Tracker().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateTrackers(opts *ListTrackersOptions, iterOpts *IteratorOptions) *TrackerIterator {
+	return c.IterateTrackersWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateTrackersWithContext performs the same operation as IterateTrackers, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateTrackersWithContext(ctx context.Context, opts *ListTrackersOptions, iterOpts *IteratorOptions) *TrackerIterator {
+	var filters ListTrackersOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListTrackersWithContext(ctx, opts.(*ListTrackersOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Trackers))
+		for i, tracker := range page.Trackers {
+			items[i] = tracker
+		}
+		return items, page.HasMore, nil
+	}
+	return &TrackerIterator{newListIterator(ctx, "trackers", CursorBeforeID, &filters, iterOpts, list)}
+}
+
+// ListTrackersInDateRange returns every tracker created between the StartDateTime and EndDateTime of opts, newest
+// first. The range is split into windows that are paged through concurrently as configured by rangeOpts, and trackers
+// returned by more than one window are only included once. The other filters of opts (e.g. Carrier) apply to every
+// window.
+func (c *Client) ListTrackersInDateRange(opts *ListTrackersOptions, rangeOpts *DateRangeOptions) (out []*Tracker, err error) {
+	return c.ListTrackersInDateRangeWithContext(context.Background(), opts, rangeOpts)
+}
+
+// ListTrackersInDateRangeWithContext performs the same operation as ListTrackersInDateRange, but
+// allows specifying a context that can interrupt the requests.
+func (c *Client) ListTrackersInDateRangeWithContext(ctx context.Context, opts *ListTrackersOptions, rangeOpts *DateRangeOptions) (out []*Tracker, err error) {
+	if opts == nil {
+		opts = &ListTrackersOptions{}
+	}
+
+	fetch := func(ctx context.Context, start, end *DateTime) ([]interface{}, error) {
+		windowOpts := *opts
+		windowOpts.BeforeID, windowOpts.AfterID = "", ""
+		windowOpts.StartDateTime, windowOpts.EndDateTime = start, end
+
+		var items []interface{}
+		it := c.IterateTrackersWithContext(ctx, &windowOpts, nil)
+		for it.Next() {
+			items = append(items, it.Tracker())
+		}
+		return items, it.Err()
+	}
+	idOf := func(item interface{}) string {
+		if tracker := item.(*Tracker); tracker != nil {
+			return tracker.ID
+		}
+		return ""
+	}
+
+	items, err := fetchDateRange(ctx, opts.StartDateTime, opts.EndDateTime, rangeOpts, fetch, idOf)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]*Tracker, len(items))
+	for i, item := range items {
+		out[i] = item.(*Tracker)
+	}
+	return out, nil
+}
+
+// ExportTrackers streams every tracker matching opts to w in the format set by exportOpts, returning the number of
+// trackers written. The trackers are fetched one page at a time as they are written, so memory use does not grow
+// with the size of the collection; set the StartDateTime and EndDateTime of opts to export a date range.
+func (c *Client) ExportTrackers(w io.Writer, opts *ListTrackersOptions, exportOpts *ExportOptions) (count int, err error) {
+	return c.ExportTrackersWithContext(context.Background(), w, opts, exportOpts)
+}
+
+// ExportTrackersWithContext performs the same operation as ExportTrackers, but
+// allows specifying a context that can interrupt the export.
+func (c *Client) ExportTrackersWithContext(ctx context.Context, w io.Writer, opts *ListTrackersOptions, exportOpts *ExportOptions) (count int, err error) {
+	it := c.IterateTrackersWithContext(ctx, opts, nil)
+	return export(w, exportOpts, reflect.TypeOf(Tracker{}), defaultTrackerExportColumns, it.pageIterator)
+}
+
 // GetTracker retrieves a Tracker object by ID.
 func (c *Client) GetTracker(trackerID string) (out *Tracker, err error) {
 	return c.GetTrackerWithContext(context.Background(), trackerID)
@@ -160,6 +160,53 @@ func (c *Client) GetNextChildUserPageWithPageSizeWithContext(ctx context.Context
 	return c.ListChildUsersWithContext(ctx, params)
 }
 
+// ChildUserIterator iterates over a paginated collection of child users. See IterateChildUsers.
+type ChildUserIterator struct {
+	*pageIterator
+}
+
+// ChildUser returns the current item of the iterator, or nil before the first call to Next and after the iteration
+// stopped.
+func (it *ChildUserIterator) ChildUser() *User {
+	user, _ := it.current.(*User)
+	return user
+}
+
+// IterateChildUsers returns an iterator over the child users matching opts, fetching the pages of the collection lazily
+// as the iteration goes.
+//
+//	it := c.IterateChildUsers(&easypost.ListOptions{PageSize: 100}, &easypost.IteratorOptions{Limit: 500})
+//	for it.Next() {
+//		fmt.Println(it.ChildUser().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateChildUsers(opts *ListOptions, iterOpts *IteratorOptions) *ChildUserIterator {
+	return c.IterateChildUsersWithContext(context.Background(), opts, iterOpts)
+}
+
+// IterateChildUsersWithContext performs the same operation as IterateChildUsers, but
+// allows specifying a context that stops the iteration when done.
+func (c *Client) IterateChildUsersWithContext(ctx context.Context, opts *ListOptions, iterOpts *IteratorOptions) *ChildUserIterator {
+	var filters ListOptions
+	if opts != nil {
+		filters = *opts
+	}
+	list := func(ctx context.Context, opts paginatedListOptions) ([]interface{}, bool, error) {
+		page, err := c.ListChildUsersWithContext(ctx, opts.(*ListOptions))
+		if err != nil {
+			return nil, false, err
+		}
+		items := make([]interface{}, len(page.Children))
+		for i, user := range page.Children {
+			items[i] = user
+		}
+		return items, page.HasMore, nil
+	}
+	return &ChildUserIterator{newListIterator(ctx, "users/children", CursorAfterID, &filters, iterOpts, list)}
+}
+
 // UpdateBrand updates the user brand.
 func (c *Client) UpdateBrand(userID string, params map[string]interface{}) (out *Brand, err error) {
 	return c.UpdateBrandWithContext(context.Background(), userID, params)